@@ -16,8 +16,8 @@ wait_for_service() {
   echo "$name is up!"
 }
 
-wait_for_service "RabbitMQ" "rabbitmq" "${AMQP_PORT:-5672}"
-wait_for_service "PostgreSQL" "db" "${POSTGRES_PORT:-5432}"
+wait_for_service "RabbitMQ" "${AMQP_HOST:-rabbitmq}" "${AMQP_PORT:-5672}"
+wait_for_service "PostgreSQL" "${POSTGRES_HOST:-db}" "${POSTGRES_PORT:-5432}"
 
 echo "All services are up - executing command"
 exec "$@"
\ No newline at end of file
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"go.uber.org/zap/zapcore"
+)
+
+type Config struct {
+	Log       LogConfig       `yaml:"log"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Postgres  PostgresConfig  `yaml:"postgres"`
+	AMQP      AMQPConfig      `yaml:"amqp"`
+	Queue     QueueConfig     `yaml:"queue"`
+	API       APIConfig       `yaml:"api"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Worker    WorkerConfig    `yaml:"worker"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level" env:"LOG_LEVEL" flag:"log-level" default:"info" usage:"log level: debug, info, warn or error"`
+}
+
+type DatabaseConfig struct {
+	URL string `yaml:"url" env:"DATABASE_URL" flag:"db" secret:"url" usage:"URL of PostgreSQL database or sqlite:// path of SQLite database, overrides postgres settings"`
+}
+
+type PostgresConfig struct {
+	Host     string `yaml:"host" env:"POSTGRES_HOST" flag:"postgres-host" default:"db" usage:"host of PostgreSQL"`
+	Port     int    `yaml:"port" env:"POSTGRES_PORT" flag:"postgres-port" default:"5432" usage:"port of PostgreSQL"`
+	User     string `yaml:"user" env:"POSTGRES_USER" flag:"postgres-user" usage:"user of PostgreSQL"`
+	Password string `yaml:"password" env:"POSTGRES_PASSWORD" flag:"postgres-password" secret:"true" usage:"password of PostgreSQL"`
+	Database string `yaml:"database" env:"POSTGRES_DB" flag:"postgres-db" usage:"name of PostgreSQL database"`
+	MaxConns int    `yaml:"max_conns" env:"POSTGRES_MAX_CONNS" flag:"postgres-max-conns" default:"10" usage:"max size of PostgreSQL connection pool"`
+}
+
+type AMQPConfig struct {
+	Host     string `yaml:"host" env:"AMQP_HOST" flag:"amqp-host" default:"rabbitmq" usage:"host of RabbitMQ"`
+	Port     int    `yaml:"port" env:"AMQP_PORT" flag:"amqp-port" default:"5672" usage:"port of RabbitMQ"`
+	User     string `yaml:"user" env:"AMQP_USER" flag:"amqp-user" usage:"user of RabbitMQ"`
+	Password string `yaml:"password" env:"AMQP_PASSWORD" flag:"amqp-password" secret:"true" usage:"password of RabbitMQ"`
+}
+
+type QueueConfig struct {
+	Backend string `yaml:"backend" env:"QUEUE_BACKEND" flag:"queue-backend" default:"rabbitmq" usage:"queue backend: rabbitmq or postgres"`
+}
+
+type APIConfig struct {
+	Addr           string `yaml:"addr" env:"API_ADDR" flag:"addr" default:":8080" usage:"address of HTTP server"`
+	MigrateOnStart bool   `yaml:"migrate_on_start" env:"MIGRATE_ON_START" flag:"migrate" usage:"apply pending migrations at startup"`
+}
+
+type SchedulerConfig struct {
+	Interval        time.Duration `yaml:"interval" env:"SCHEDULER_INTERVAL" flag:"scheduler-interval" default:"10s" usage:"interval of scheduler loop"`
+	OutboxInterval  time.Duration `yaml:"outbox_interval" env:"OUTBOX_INTERVAL" flag:"outbox-interval" default:"500ms" usage:"interval of outbox relay"`
+	OutboxBatchSize int           `yaml:"outbox_batch_size" env:"OUTBOX_BATCH_SIZE" flag:"outbox-batch-size" default:"100" usage:"max number of outbox messages relayed at once"`
+}
+
+type WorkerConfig struct {
+	Count int    `yaml:"count" env:"NUMOFWORKERS" flag:"workers" default:"3" usage:"number of workers per type of task"`
+	Types string `yaml:"types" env:"WORKER_TYPES" flag:"worker-types" usage:"comma-separated types of tasks served by worker with optional number of workers, e.g. process_image:4,send_email (default all types)"`
+}
+
+type MetricsConfig struct {
+	Port int `yaml:"port" env:"METRICS_PORT" flag:"metrics-port" default:"9090" usage:"port of metrics and probes server"`
+}
+
+func Default() *Config {
+	var c Config
+	if err := walk(&c, func(f field) error {
+		if f.defaultValue == "" {
+			return nil
+		}
+		return f.set(f.defaultValue)
+	}); err != nil {
+		panic("invalid default of config: " + err.Error())
+	}
+	return &c
+}
+
+func (c *Config) DatabaseURL() string {
+	if c.Database.URL != "" {
+		return c.Database.URL
+	}
+	return c.Postgres.URL()
+}
+
+func (c *PostgresConfig) URL() string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.User, c.Password),
+		Host:   net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:   "/" + c.Database,
+	}
+	if c.MaxConns > 0 {
+		u.RawQuery = url.Values{"pool_max_conns": {strconv.Itoa(c.MaxConns)}}.Encode()
+	}
+	return u.String()
+}
+
+func (c *AMQPConfig) URL() string {
+	u := url.URL{
+		Scheme: "amqp",
+		User:   url.UserPassword(c.User, c.Password),
+		Host:   net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:   "/",
+	}
+	return u.String()
+}
+
+func (c *WorkerConfig) Pools() (map[string]int, error) {
+	pools := make(map[string]int)
+	if strings.TrimSpace(c.Types) == "" {
+		for _, taskType := range task.Types() {
+			pools[taskType] = c.Count
+		}
+		return pools, nil
+	}
+
+	for _, item := range strings.Split(c.Types, ",") {
+		taskType, count, hasCount := strings.Cut(strings.TrimSpace(item), ":")
+		if !task.ValidateType(taskType) {
+			return nil, fmt.Errorf("unknown type of task %q", taskType)
+		}
+		if _, ok := pools[taskType]; ok {
+			return nil, fmt.Errorf("duplicate type of task %q", taskType)
+		}
+
+		n := c.Count
+		if hasCount {
+			var err error
+			if n, err = strconv.Atoi(count); err != nil || n <= 0 {
+				return nil, fmt.Errorf("number of %s workers should be positive integer, got %q", taskType, count)
+			}
+		}
+		pools[taskType] = n
+	}
+	return pools, nil
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, err := zapcore.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %v", err))
+	}
+
+	if c.Database.URL == "" {
+		if c.Postgres.Host == "" {
+			errs = append(errs, errors.New("postgres.host should not be empty"))
+		}
+		if c.Postgres.User == "" {
+			errs = append(errs, errors.New("postgres.user should not be empty"))
+		}
+		if c.Postgres.Database == "" {
+			errs = append(errs, errors.New("postgres.database should not be empty"))
+		}
+	}
+	errs = append(errs, validatePort("postgres.port", c.Postgres.Port))
+	if c.Postgres.MaxConns < 0 {
+		errs = append(errs, errors.New("postgres.max_conns should not be negative"))
+	}
+
+	if c.Queue.Backend == "rabbitmq" {
+		if c.AMQP.Host == "" {
+			errs = append(errs, errors.New("amqp.host should not be empty"))
+		}
+		errs = append(errs, validatePort("amqp.port", c.AMQP.Port))
+	} else if c.Queue.Backend != "postgres" {
+		errs = append(errs, fmt.Errorf("queue.backend should be rabbitmq or postgres, got %q", c.Queue.Backend))
+	}
+
+	if _, _, err := net.SplitHostPort(c.API.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("api.addr: %v", err))
+	}
+
+	if c.Scheduler.Interval <= 0 {
+		errs = append(errs, errors.New("scheduler.interval should be positive"))
+	}
+	if c.Scheduler.OutboxInterval <= 0 {
+		errs = append(errs, errors.New("scheduler.outbox_interval should be positive"))
+	}
+	if c.Scheduler.OutboxBatchSize <= 0 {
+		errs = append(errs, errors.New("scheduler.outbox_batch_size should be positive"))
+	}
+
+	if c.Worker.Count <= 0 {
+		errs = append(errs, errors.New("worker.count should be positive"))
+	}
+	if _, err := c.Worker.Pools(); err != nil {
+		errs = append(errs, fmt.Errorf("worker.types: %v", err))
+	}
+
+	errs = append(errs, validatePort("metrics.port", c.Metrics.Port))
+
+	return errors.Join(errs...)
+}
+
+func validatePort(name string, port int) error {
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("%s should be in range 1-65535, got %d", name, port)
+	}
+	return nil
+}
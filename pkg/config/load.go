@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+type field struct {
+	path         string
+	env          string
+	flag         string
+	defaultValue string
+	usage        string
+	secret       string
+	value        reflect.Value
+}
+
+func (f field) set(s string) error {
+	switch {
+	case f.value.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%s: %v", f.path, err)
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(s)
+	case f.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%s: invalid integer %q", f.path, s)
+		}
+		f.value.SetInt(int64(n))
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%s: invalid boolean %q", f.path, s)
+		}
+		f.value.SetBool(b)
+	default:
+		return fmt.Errorf("%s: unsupported type %s", f.path, f.value.Type())
+	}
+	return nil
+}
+
+func walk(c *Config, fn func(f field) error) error {
+	sections := reflect.ValueOf(c).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		sectionName := sections.Type().Field(i).Tag.Get("yaml")
+
+		for j := 0; j < section.NumField(); j++ {
+			sf := section.Type().Field(j)
+			f := field{
+				path:         sectionName + "." + sf.Tag.Get("yaml"),
+				env:          sf.Tag.Get("env"),
+				flag:         sf.Tag.Get("flag"),
+				defaultValue: sf.Tag.Get("default"),
+				usage:        sf.Tag.Get("usage"),
+				secret:       sf.Tag.Get("secret"),
+				value:        section.Field(j),
+			}
+			if err := fn(f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(s string) error {
+	v.value = s
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+func (c *Config) Load(name string, args []string) ([]string, error) {
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load .env file: %v", err)
+	}
+
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to YAML or JSON config file")
+
+	flags := make(map[string]*flagValue)
+	walk(c, func(f field) error {
+		v := &flagValue{isBool: f.value.Kind() == reflect.Bool}
+		if f.secret == "" {
+			v.value = fmt.Sprint(f.value.Interface())
+		}
+		fs.Var(v, f.flag, fmt.Sprintf("%s (env %s)", f.usage, f.env))
+		flags[f.flag] = v
+		return nil
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		if err := c.loadFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	err := walk(c, func(f field) error {
+		if value, ok := os.LookupEnv(f.env); ok && value != "" {
+			if err := f.set(value); err != nil {
+				return fmt.Errorf("invalid env %s: %v", f.env, err)
+			}
+		}
+		if set[f.flag] {
+			if err := f.set(flags[f.flag].value); err != nil {
+				return fmt.Errorf("invalid flag -%s: %v", f.flag, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return fs.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %v", err)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode config file: %v", err)
+	}
+
+	return nil
+}
+
+func (c *Config) Redacted() map[string]interface{} {
+	redacted := make(map[string]interface{})
+	walk(c, func(f field) error {
+		section, key, _ := strings.Cut(f.path, ".")
+		values, ok := redacted[section].(map[string]interface{})
+		if !ok {
+			values = make(map[string]interface{})
+			redacted[section] = values
+		}
+
+		value := f.value.Interface()
+		switch {
+		case f.value.Type() == durationType:
+			value = f.value.Interface().(time.Duration).String()
+		case f.secret == "true" && f.value.String() != "":
+			value = "***"
+		case f.secret == "url":
+			value = redactURL(f.value.String())
+		}
+
+		values[key] = value
+		return nil
+	})
+	return redacted
+}
+
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "***"
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const checkTimeout = 2 * time.Second
+
+type Check func(ctx context.Context) error
+
+type Checker struct {
+	mu        sync.RWMutex
+	liveness  map[string]Check
+	readiness map[string]Check
+}
+
+type response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		liveness:  map[string]Check{},
+		readiness: map[string]Check{},
+	}
+}
+
+func (c *Checker) AddLiveness(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.liveness[name] = check
+}
+
+func (c *Checker) AddReadiness(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readiness[name] = check
+}
+
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		writeResponse(w, run(r.Context(), c.liveness))
+	})
+}
+
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		writeResponse(w, run(r.Context(), c.readiness))
+	})
+}
+
+func run(ctx context.Context, checks map[string]Check) response {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	resp := response{Status: "ok"}
+	if len(checks) == 0 {
+		return resp
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	resp.Checks = make(map[string]string, len(checks))
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			result := "ok"
+			if err := check(ctx); err != nil {
+				result = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			resp.Checks[name] = result
+			if result != "ok" {
+				resp.Status = "unavailable"
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return resp
+}
+
+func writeResponse(w http.ResponseWriter, resp response) {
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
@@ -11,6 +11,7 @@ import (
 var (
 	once   sync.Once
 	logger *zap.Logger
+	level  zap.AtomicLevel
 )
 
 func InitLogger() {
@@ -21,6 +22,8 @@ func InitLogger() {
 			pae.AppendString(t.UTC().Format("2006-01-02T15:04:05.000Z"))
 		}
 
+		level = config.Level
+
 		var err error
 		logger, err = config.Build()
 		if err != nil {
@@ -35,3 +38,14 @@ func GetLogger() *zap.Logger {
 	}
 	return logger
 }
+
+func SetLevel(l string) error {
+	parsed, err := zapcore.ParseLevel(l)
+	if err != nil {
+		return err
+	}
+
+	GetLogger()
+	level.SetLevel(parsed)
+	return nil
+}
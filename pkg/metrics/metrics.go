@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "taskflow"
+
+var (
+	TasksCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_created_total",
+		Help:      "Number of created tasks.",
+	}, []string{"type"})
+
+	TasksPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_published_total",
+		Help:      "Number of tasks published to the queue.",
+	}, []string{"type"})
+
+	TasksCompleted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_completed_total",
+		Help:      "Number of successfully completed tasks.",
+	}, []string{"type"})
+
+	TasksFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_failed_total",
+		Help:      "Number of tasks that exhausted their retries.",
+	}, []string{"type"})
+
+	TaskExecutionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "task_execution_duration_seconds",
+		Help:      "Duration of a single task execution by executer.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"type", "result"})
+
+	SchedulerLoopDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduler_loop_duration_seconds",
+		Help:      "Duration of a single iteration of a scheduler loop.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"loop"})
+
+	SchedulerBatchSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduler_batch_size",
+		Help:      "Number of items processed by a single iteration of a scheduler loop.",
+		Buckets:   []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000},
+	}, []string{"loop"})
+
+	QueuePublishErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_publish_errors_total",
+		Help:      "Number of failed attempts to publish a task to the queue.",
+	})
+
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of handled HTTP requests.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of handled HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
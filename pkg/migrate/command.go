@@ -0,0 +1,57 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+func RunCommand(ctx context.Context, m *Migrator, args []string, w io.Writer) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected arguments of migrate command: %v", args[1:])
+	}
+
+	switch command {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(w, "applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(w, "no pending migrations")
+		}
+
+	case "down":
+		reverted, err := m.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "reverted %04d_%s\n", reverted.Version, reverted.Name)
+
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied at " + s.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", command)
+	}
+
+	return nil
+}
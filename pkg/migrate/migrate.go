@@ -0,0 +1,203 @@
+package migrate
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+var ErrNoMigrationsApplied = errors.New("no migrations applied")
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+type driver interface {
+	lock(ctx context.Context) (func(), error)
+	createTable(ctx context.Context) error
+	applied(ctx context.Context) (map[int64]time.Time, error)
+	up(ctx context.Context, m Migration) error
+	down(ctx context.Context, m Migration) error
+}
+
+type Migrator struct {
+	driver     driver
+	migrations []Migration
+}
+
+func newMigrator(d driver, dialect string) (*Migrator, error) {
+	migrations, err := loadMigrations(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		driver:     d,
+		migrations: migrations,
+	}, nil
+}
+
+func loadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+
+	entries, err := fs.ReadDir(migrationsFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %v", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid name of migration file %q", entry.Name())
+		}
+
+		versionStr, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid version of migration file %q", entry.Name())
+		}
+
+		content, err := fs.ReadFile(migrationsFS, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file %q: %v", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d should have both up and down files", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	unlock, err := m.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.driver.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := m.driver.up(ctx, migration); err != nil {
+			return done, fmt.Errorf("failed to apply migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	unlock, err := m.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.driver.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if err := m.driver.down(ctx, migration); err != nil {
+			return nil, fmt.Errorf("failed to revert migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+		return &migration, nil
+	}
+
+	return nil, ErrNoMigrationsApplied
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	unlock, err := m.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.driver.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		s := Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			s.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, s)
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) prepare(ctx context.Context) (func(), error) {
+	unlock, err := m.driver.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.driver.createTable(ctx); err != nil {
+		unlock()
+		return nil, err
+	}
+
+	return unlock, nil
+}
@@ -0,0 +1,138 @@
+package migrate_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/migrate"
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+)
+
+func newTestMigrator(t *testing.T) (*migrate.Migrator, *sql.DB) {
+	db, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "taskflow.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migrate.NewSQLiteMigrator(db)
+	require.NoError(t, err)
+
+	return m, db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	var exists bool
+	err := db.QueryRow("select exists (select 1 from sqlite_master where type = 'table' and name = ?)", name).Scan(&exists)
+	require.NoError(t, err)
+	return exists
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	m, db := newTestMigrator(t)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, statuses)
+	for _, s := range statuses {
+		assert.Nil(t, s.AppliedAt)
+	}
+
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(statuses))
+	assert.True(t, tableExists(t, db, "tasks"))
+
+	applied, err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err = m.Status(ctx)
+	require.NoError(t, err)
+	for _, s := range statuses {
+		assert.NotNil(t, s.AppliedAt)
+	}
+
+	for range statuses {
+		_, err := m.Down(ctx)
+		require.NoError(t, err)
+	}
+	assert.False(t, tableExists(t, db, "tasks"))
+
+	_, err = m.Down(ctx)
+	assert.Equal(t, migrate.ErrNoMigrationsApplied, err)
+}
+
+func TestMigratorMovesErrorTasksToFailed(t *testing.T) {
+	ctx := context.Background()
+	m, db := newTestMigrator(t)
+
+	_, err := m.Up(ctx)
+	require.NoError(t, err)
+	last, err := m.Down(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(16), last.Version)
+
+	_, err = db.Exec("insert into users (id, email, password_hash) values (1, 'test@test.com', 'hash')")
+	require.NoError(t, err)
+	_, err = db.Exec("insert into tasks (id, user_id, type, payload, status) values ('task-1', 1, 'send_email', '{}', 'error')")
+	require.NoError(t, err)
+
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	var status string
+	require.NoError(t, db.QueryRow("select status from tasks where id = 'task-1'").Scan(&status))
+	assert.Equal(t, "failed", status)
+}
+
+func TestDialectsShareVersions(t *testing.T) {
+	names := func(dialect string) []string {
+		entries, err := os.ReadDir(filepath.Join("migrations", dialect))
+		require.NoError(t, err)
+
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	assert.Equal(t, names("postgres"), names("sqlite"))
+}
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"default is up", nil, "applied 0001_init\n", false},
+		{"status", []string{"status"}, "0001_init\tpending\n", false},
+		{"down without applied migrations", []string{"down"}, "", true},
+		{"unknown command", []string{"redo"}, "", true},
+		{"extra arguments", []string{"up", "1"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := newTestMigrator(t)
+
+			var out bytes.Buffer
+			err := migrate.RunCommand(context.Background(), m, tt.args, &out)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, out.String(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS tr_log_tasks ON tasks;
+DROP FUNCTION IF EXISTS log_tasks();
+
+DROP TABLE IF EXISTS task_logs;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS task_attempts (
+    id SERIAL PRIMARY KEY,
+    task_id UUID REFERENCES tasks(id),
+    attempt INTEGER NOT NULL,
+    worker_id TEXT NOT NULL,
+    started_at TIMESTAMP DEFAULT now(),
+    finished_at TIMESTAMP,
+    duration_ms BIGINT,
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS task_attempts_task_id_idx ON task_attempts (task_id);
@@ -0,0 +1,21 @@
+CREATE OR REPLACE FUNCTION log_tasks()
+RETURNS TRIGGER AS $$
+BEGIN
+    CASE
+        WHEN TG_OP = 'INSERT' THEN
+            INSERT INTO task_logs (task_id, message)
+            VALUES (NEW.id, 'task has been created');
+
+        WHEN TG_OP = 'UPDATE' THEN
+            INSERT INTO task_logs (task_id, message)
+            VALUES (
+                NEW.id,
+                'updated status from "' || OLD.status || '" to "' || NEW.status || '"'
+            );
+    END CASE;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS task_logs_task_id_idx;
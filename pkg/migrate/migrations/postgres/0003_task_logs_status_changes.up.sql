@@ -0,0 +1,24 @@
+CREATE INDEX IF NOT EXISTS task_logs_task_id_idx ON task_logs (task_id);
+
+CREATE OR REPLACE FUNCTION log_tasks()
+RETURNS TRIGGER AS $$
+BEGIN
+    CASE
+        WHEN TG_OP = 'INSERT' THEN
+            INSERT INTO task_logs (task_id, message)
+            VALUES (NEW.id, 'task has been created');
+
+        WHEN TG_OP = 'UPDATE' AND OLD.status IS DISTINCT FROM NEW.status THEN
+            INSERT INTO task_logs (task_id, message)
+            VALUES (
+                NEW.id,
+                'updated status from "' || OLD.status || '" to "' || NEW.status || '"'
+            );
+
+        ELSE
+            NULL;
+    END CASE;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
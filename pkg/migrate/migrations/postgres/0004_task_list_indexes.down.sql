@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS tasks_user_id_run_at_idx;
+DROP INDEX IF EXISTS tasks_user_id_created_at_idx;
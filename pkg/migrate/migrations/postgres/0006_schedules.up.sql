@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS schedules (
+    id UUID PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id),
+    cron_expr TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    max_retries INTEGER DEFAULT 3,
+    retry_policy JSONB,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS schedules_next_run_at_idx ON schedules (next_run_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS workflow_tasks;
+DROP TABLE IF EXISTS workflows;
+DROP TABLE IF EXISTS task_dependencies;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS result;
@@ -0,0 +1,22 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS result JSONB;
+
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id UUID REFERENCES tasks(id),
+    depends_on UUID REFERENCES tasks(id),
+    PRIMARY KEY (task_id, depends_on)
+);
+
+CREATE INDEX IF NOT EXISTS task_dependencies_depends_on_idx ON task_dependencies (depends_on);
+
+CREATE TABLE IF NOT EXISTS workflows (
+    id UUID PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS workflow_tasks (
+    workflow_id UUID REFERENCES workflows(id),
+    name TEXT NOT NULL,
+    task_id UUID UNIQUE REFERENCES tasks(id),
+    PRIMARY KEY (workflow_id, name)
+);
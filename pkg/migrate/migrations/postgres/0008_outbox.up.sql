@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    task_id UUID REFERENCES tasks(id),
+    created_at TIMESTAMP DEFAULT now(),
+    locked_until TIMESTAMP,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unsent_idx ON outbox (id) WHERE sent_at IS NULL;
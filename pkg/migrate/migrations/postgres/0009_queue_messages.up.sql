@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS queue_messages (
+    id BIGSERIAL PRIMARY KEY,
+    body JSONB NOT NULL,
+    deliveries INTEGER DEFAULT 0,
+    visible_at TIMESTAMP DEFAULT now(),
+    created_at TIMESTAMP DEFAULT now(),
+    dead_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS queue_messages_visible_idx ON queue_messages (visible_at, id) WHERE dead_at IS NULL;
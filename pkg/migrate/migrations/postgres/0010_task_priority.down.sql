@@ -0,0 +1,6 @@
+DROP INDEX queue_messages_visible_idx;
+CREATE INDEX queue_messages_visible_idx ON queue_messages (visible_at, id) WHERE dead_at IS NULL;
+
+ALTER TABLE queue_messages DROP COLUMN priority;
+
+ALTER TABLE tasks DROP COLUMN priority;
@@ -0,0 +1,6 @@
+ALTER TABLE tasks ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
+
+ALTER TABLE queue_messages ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
+
+DROP INDEX queue_messages_visible_idx;
+CREATE INDEX queue_messages_visible_idx ON queue_messages (priority DESC, id) WHERE dead_at IS NULL;
@@ -0,0 +1,4 @@
+DROP INDEX queue_messages_visible_idx;
+CREATE INDEX queue_messages_visible_idx ON queue_messages (priority DESC, id) WHERE dead_at IS NULL;
+
+ALTER TABLE queue_messages DROP COLUMN task_type;
@@ -0,0 +1,5 @@
+ALTER TABLE queue_messages ADD COLUMN task_type TEXT NOT NULL DEFAULT '';
+UPDATE queue_messages SET task_type = COALESCE(body->>'type', '');
+
+DROP INDEX queue_messages_visible_idx;
+CREATE INDEX queue_messages_visible_idx ON queue_messages (task_type, priority DESC, id) WHERE dead_at IS NULL;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id INTEGER REFERENCES users(id),
+    key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    task_id UUID REFERENCES tasks(id),
+    response JSONB,
+    created_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (user_id, key)
+);
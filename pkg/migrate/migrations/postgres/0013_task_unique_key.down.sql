@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS tasks_unique_key_idx;
+
+ALTER TABLE tasks DROP COLUMN unique_until;
+ALTER TABLE tasks DROP COLUMN unique_key;
@@ -0,0 +1,5 @@
+ALTER TABLE tasks ADD COLUMN unique_key TEXT;
+ALTER TABLE tasks ADD COLUMN unique_until TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS tasks_unique_key_idx ON tasks (user_id, unique_key)
+    WHERE unique_key IS NOT NULL AND status IN ('queued', 'postponed', 'processing', 'waiting');
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS tasks_unique_key_idx;
+
+UPDATE tasks SET unique_key = NULL WHERE unique_active IS NULL;
+ALTER TABLE tasks DROP COLUMN unique_active;
+
+CREATE UNIQUE INDEX IF NOT EXISTS tasks_unique_key_idx ON tasks (user_id, unique_key)
+    WHERE unique_key IS NOT NULL AND status IN ('queued', 'postponed', 'processing', 'waiting');
@@ -0,0 +1 @@
+-- failed tasks that had the legacy error status are not restored.
@@ -0,0 +1 @@
+UPDATE tasks SET status = 'failed' WHERE status = 'error';
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS task_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id TEXT REFERENCES tasks(id),
+    attempt INTEGER NOT NULL,
+    worker_id TEXT NOT NULL,
+    started_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    finished_at TIMESTAMP,
+    duration_ms INTEGER,
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS task_attempts_task_id_idx ON task_attempts (task_id);
@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS tr_log_tasks_update;
+
+CREATE TRIGGER tr_log_tasks_update
+AFTER UPDATE ON tasks
+FOR EACH ROW
+BEGIN
+    INSERT INTO task_logs (task_id, message)
+    VALUES (
+        NEW.id,
+        'updated status from "' || OLD.status || '" to "' || NEW.status || '"'
+    );
+END;
+
+DROP INDEX IF EXISTS task_logs_task_id_idx;
@@ -0,0 +1,15 @@
+CREATE INDEX IF NOT EXISTS task_logs_task_id_idx ON task_logs (task_id);
+
+DROP TRIGGER IF EXISTS tr_log_tasks_update;
+
+CREATE TRIGGER tr_log_tasks_update
+AFTER UPDATE OF status ON tasks
+FOR EACH ROW
+WHEN OLD.status IS NOT NEW.status
+BEGIN
+    INSERT INTO task_logs (task_id, message)
+    VALUES (
+        NEW.id,
+        'updated status from "' || OLD.status || '" to "' || NEW.status || '"'
+    );
+END;
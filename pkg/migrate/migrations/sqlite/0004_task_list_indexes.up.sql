@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS tasks_user_id_created_at_idx ON tasks (user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS tasks_user_id_run_at_idx ON tasks (user_id, run_at, id);
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN retry_policy TEXT;
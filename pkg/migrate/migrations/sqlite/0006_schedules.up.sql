@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS schedules (
+    id TEXT PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id),
+    cron_expr TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    max_retries INTEGER DEFAULT 3,
+    retry_policy TEXT,
+    next_run_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS schedules_next_run_at_idx ON schedules (next_run_at);
@@ -0,0 +1,22 @@
+ALTER TABLE tasks ADD COLUMN result TEXT;
+
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id TEXT REFERENCES tasks(id),
+    depends_on TEXT REFERENCES tasks(id),
+    PRIMARY KEY (task_id, depends_on)
+);
+
+CREATE INDEX IF NOT EXISTS task_dependencies_depends_on_idx ON task_dependencies (depends_on);
+
+CREATE TABLE IF NOT EXISTS workflows (
+    id TEXT PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS workflow_tasks (
+    workflow_id TEXT REFERENCES workflows(id),
+    name TEXT NOT NULL,
+    task_id TEXT UNIQUE REFERENCES tasks(id),
+    PRIMARY KEY (workflow_id, name)
+);
@@ -0,0 +1 @@
+-- queue_messages is used only by the PostgreSQL queue backend.
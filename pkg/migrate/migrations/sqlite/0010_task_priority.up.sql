@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
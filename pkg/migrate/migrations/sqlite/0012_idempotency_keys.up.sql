@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id INTEGER REFERENCES users(id),
+    key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    task_id TEXT REFERENCES tasks(id),
+    response TEXT,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (user_id, key)
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS tasks_unique_key_idx;
+
+ALTER TABLE tasks ADD COLUMN unique_active BOOLEAN;
+UPDATE tasks SET unique_active = true WHERE unique_key IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS tasks_unique_key_idx ON tasks (user_id, unique_key)
+    WHERE unique_active AND status IN ('queued', 'postponed', 'processing', 'waiting');
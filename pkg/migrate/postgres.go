@@ -0,0 +1,109 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const advisoryLockID = 0x7461736b666c6f77
+
+type postgresDriver struct {
+	pool *pgxpool.Pool
+	conn *pgxpool.Conn
+}
+
+func NewPostgresMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+	return newMigrator(&postgresDriver{pool: pool}, "postgres")
+}
+
+func (d *postgresDriver) lock(ctx context.Context) (func(), error) {
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %v", err)
+	}
+
+	if _, err := conn.Exec(ctx, "select pg_advisory_lock($1)", int64(advisoryLockID)); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("failed to acquire advisory lock: %v", err)
+	}
+
+	d.conn = conn
+	return func() {
+		d.conn = nil
+		if _, err := conn.Exec(context.Background(), "select pg_advisory_unlock($1)", int64(advisoryLockID)); err != nil {
+			conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+	}, nil
+}
+
+func (d *postgresDriver) createTable(ctx context.Context) error {
+	query := `create table if not exists schema_migrations (
+		version bigint primary key,
+		name text not null,
+		applied_at timestamptz not null default now()
+	)`
+
+	if _, err := d.conn.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %v", err)
+	}
+
+	return nil
+}
+
+func (d *postgresDriver) applied(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := d.conn.Query(ctx, "select version, applied_at from schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to select applied migrations: %v", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migrations: %v", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to select applied migrations: %v", err)
+	}
+
+	return applied, nil
+}
+
+func (d *postgresDriver) up(ctx context.Context, m Migration) error {
+	return d.exec(ctx, m.Up, "insert into schema_migrations (version, name) values ($1, $2)", m.Version, m.Name)
+}
+
+func (d *postgresDriver) down(ctx context.Context, m Migration) error {
+	return d.exec(ctx, m.Down, "delete from schema_migrations where version = $1", m.Version)
+}
+
+func (d *postgresDriver) exec(ctx context.Context, migration, query string, args ...interface{}) error {
+	tx, err := d.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, migration); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update schema_migrations: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
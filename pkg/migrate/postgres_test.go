@@ -0,0 +1,121 @@
+package migrate_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/migrate"
+)
+
+func newTestPostgresPool(t *testing.T) *pgxpool.Pool {
+	url := os.Getenv("TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+
+	admin, err := pgxpool.New(ctx, url)
+	require.NoError(t, err)
+	t.Cleanup(admin.Close)
+
+	_, err = admin.Exec(ctx, "create schema "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin.Exec(context.Background(), "drop schema "+schema+" cascade")
+	})
+
+	config, err := pgxpool.ParseConfig(url)
+	require.NoError(t, err)
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+func tableColumns(t *testing.T, pool *pgxpool.Pool) map[string][]string {
+	query := `select table_name, column_name from information_schema.columns
+	where table_schema = current_schema() and table_name <> 'schema_migrations'
+	order by table_name, ordinal_position`
+
+	rows, err := pool.Query(context.Background(), query)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	columns := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		require.NoError(t, rows.Scan(&table, &column))
+		columns[table] = append(columns[table], column)
+	}
+	require.NoError(t, rows.Err())
+
+	return columns
+}
+
+func TestPostgresMigratorUpgradesBaselineSchema(t *testing.T) {
+	ctx := context.Background()
+
+	fresh := newTestPostgresPool(t)
+	m, err := migrate.NewPostgresMigrator(fresh)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	baseline, err := os.ReadFile("testdata/postgres_baseline.sql")
+	require.NoError(t, err)
+
+	upgraded := newTestPostgresPool(t)
+	_, err = upgraded.Exec(ctx, string(baseline))
+	require.NoError(t, err)
+
+	var userID int
+	err = upgraded.QueryRow(ctx, "insert into users (email, password_hash) values ('test@test.com', 'hash') returning id").Scan(&userID)
+	require.NoError(t, err)
+
+	taskID := uuid.New()
+	_, err = upgraded.Exec(ctx, "insert into tasks (id, user_id, type, payload) values ($1, $2, 'send_email', '{}')", taskID, userID)
+	require.NoError(t, err)
+
+	errorTaskID := uuid.New()
+	_, err = upgraded.Exec(ctx, "insert into tasks (id, user_id, type, payload, status) values ($1, $2, 'send_email', '{}', 'error')", errorTaskID, userID)
+	require.NoError(t, err)
+
+	m, err = migrate.NewPostgresMigrator(upgraded)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, tableColumns(t, fresh), tableColumns(t, upgraded))
+
+	var status string
+	var priority int
+	err = upgraded.QueryRow(ctx, "select status, priority from tasks where id = $1", taskID).Scan(&status, &priority)
+	require.NoError(t, err)
+	assert.Equal(t, "queued", status)
+	assert.Equal(t, 0, priority)
+
+	err = upgraded.QueryRow(ctx, "select status from tasks where id = $1", errorTaskID).Scan(&status)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", status)
+
+	_, err = upgraded.Exec(ctx, "update tasks set retries = 1 where id = $1", taskID)
+	require.NoError(t, err)
+
+	var logs int
+	err = upgraded.QueryRow(ctx, "select count(*) from task_logs where task_id = $1", taskID).Scan(&logs)
+	require.NoError(t, err)
+	assert.Equal(t, 1, logs)
+}
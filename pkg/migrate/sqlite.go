@@ -0,0 +1,98 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type sqliteDriver struct {
+	db *sql.DB
+}
+
+func NewSQLiteMigrator(db *sql.DB) (*Migrator, error) {
+	return newMigrator(&sqliteDriver{db: db}, "sqlite")
+}
+
+func (d *sqliteDriver) lock(ctx context.Context) (func(), error) {
+	return func() {}, nil
+}
+
+func (d *sqliteDriver) createTable(ctx context.Context) error {
+	query := `create table if not exists schema_migrations (
+		version integer primary key,
+		name text not null,
+		applied_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+	)`
+
+	if _, err := d.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %v", err)
+	}
+
+	return nil
+}
+
+func (d *sqliteDriver) applied(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := d.db.QueryContext(ctx, "select version, applied_at from schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to select applied migrations: %v", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migrations: %v", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to select applied migrations: %v", err)
+	}
+
+	return applied, nil
+}
+
+func (d *sqliteDriver) up(ctx context.Context, m Migration) error {
+	return d.exec(ctx, m, false, m.Up, "insert into schema_migrations (version, name) values (?, ?)", m.Version, m.Name)
+}
+
+func (d *sqliteDriver) down(ctx context.Context, m Migration) error {
+	return d.exec(ctx, m, true, m.Down, "delete from schema_migrations where version = ?", m.Version)
+}
+
+func (d *sqliteDriver) exec(ctx context.Context, m Migration, wantApplied bool, migration, query string, args ...interface{}) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var applied bool
+	err = tx.QueryRowContext(ctx, "select exists (select 1 from schema_migrations where version = ?)", m.Version).Scan(&applied)
+	if err != nil {
+		return fmt.Errorf("failed to select state of migration: %v", err)
+	}
+
+	if applied != wantApplied {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, migration); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update schema_migrations: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
@@ -49,4 +49,4 @@ CREATE OR REPLACE TRIGGER tr_log_tasks
 AFTER INSERT OR UPDATE
 ON tasks
 FOR EACH ROW
-EXECUTE FUNCTION log_tasks();
\ No newline at end of file
+EXECUTE FUNCTION log_tasks();
@@ -0,0 +1,21 @@
+package queue
+
+type Delivery struct {
+	Body        []byte
+	Redelivered bool
+	ack         func() error
+	nack        func(requeue bool) error
+}
+
+func (d *Delivery) Ack() error {
+	return d.ack()
+}
+
+func (d *Delivery) Nack(requeue bool) error {
+	return d.nack(requeue)
+}
+
+type Consumer interface {
+	Deliveries() <-chan Delivery
+	Close() error
+}
@@ -0,0 +1,272 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+type memoryMessage struct {
+	body        []byte
+	taskType    string
+	priority    uint8
+	redelivered bool
+}
+
+type MemoryQueue struct {
+	mu      sync.Mutex
+	pending map[string][]memoryMessage
+	dead    [][]byte
+	wakeup  chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewMemoryQueue() *MemoryQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &MemoryQueue{
+		pending: make(map[string][]memoryMessage),
+		wakeup:  make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+func (q *MemoryQueue) Publish(t *task.Task) error {
+	return q.PublishBatch([]*task.Task{t})[0]
+}
+
+func (q *MemoryQueue) PublishBatch(tasks []*task.Task) []error {
+	errs := make([]error, len(tasks))
+
+	if q.ctx.Err() != nil {
+		for i := range errs {
+			errs[i] = ErrQueueClosed
+		}
+		return errs
+	}
+
+	messages := make([]memoryMessage, 0, len(tasks))
+	for i, t := range tasks {
+		body, err := json.Marshal(t)
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to marshal task: %v", err)
+			continue
+		}
+		messages = append(messages, memoryMessage{body: body, taskType: t.Type, priority: t.Priority})
+	}
+
+	q.mu.Lock()
+	for _, m := range messages {
+		q.insertLocked(m, false)
+	}
+	q.broadcastLocked()
+	q.mu.Unlock()
+
+	return errs
+}
+
+func (q *MemoryQueue) PublishRaw(taskType string, body []byte) error {
+	if q.ctx.Err() != nil {
+		return ErrQueueClosed
+	}
+
+	q.mu.Lock()
+	q.insertLocked(memoryMessage{body: body, taskType: taskType}, false)
+	q.broadcastLocked()
+	q.mu.Unlock()
+
+	return nil
+}
+
+func (q *MemoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := 0
+	for _, pending := range q.pending {
+		n += len(pending)
+	}
+	return n
+}
+
+func (q *MemoryQueue) DeadLetters() [][]byte {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([][]byte(nil), q.dead...)
+}
+
+func (q *MemoryQueue) insertLocked(m memoryMessage, front bool) {
+	pending := q.pending[m.taskType]
+	i := sort.Search(len(pending), func(i int) bool {
+		if front {
+			return pending[i].priority <= m.priority
+		}
+		return pending[i].priority < m.priority
+	})
+	q.pending[m.taskType] = slices.Insert(pending, i, m)
+}
+
+func (q *MemoryQueue) pop(taskType string) (*memoryMessage, <-chan struct{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending := q.pending[taskType]
+	if len(pending) == 0 {
+		return nil, q.wakeup
+	}
+
+	m := pending[0]
+	q.pending[taskType] = pending[1:]
+	return &m, nil
+}
+
+func (q *MemoryQueue) requeue(m memoryMessage) {
+	m.redelivered = true
+
+	q.mu.Lock()
+	q.insertLocked(m, true)
+	q.broadcastLocked()
+	q.mu.Unlock()
+}
+
+func (q *MemoryQueue) deadLetter(body []byte) {
+	q.mu.Lock()
+	q.dead = append(q.dead, body)
+	q.mu.Unlock()
+}
+
+func (q *MemoryQueue) broadcastLocked() {
+	close(q.wakeup)
+	q.wakeup = make(chan struct{})
+}
+
+func (q *MemoryQueue) NewConsumer(taskType string) (Consumer, error) {
+	if q.ctx.Err() != nil {
+		return nil, ErrQueueClosed
+	}
+
+	if !task.ValidateType(taskType) {
+		return nil, fmt.Errorf("unknown type of task %q", taskType)
+	}
+
+	ctx, cancel := context.WithCancel(q.ctx)
+
+	c := &memoryConsumer{
+		q:          q,
+		taskType:   taskType,
+		deliveries: make(chan Delivery),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+
+	go c.run()
+
+	return c, nil
+}
+
+func (q *MemoryQueue) Check(ctx context.Context) error {
+	if q.ctx.Err() != nil {
+		return ErrQueueClosed
+	}
+
+	return nil
+}
+
+func (q *MemoryQueue) Close() error {
+	q.cancel()
+
+	return nil
+}
+
+type memoryConsumer struct {
+	q          *MemoryQueue
+	taskType   string
+	deliveries chan Delivery
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *memoryConsumer) Deliveries() <-chan Delivery {
+	return c.deliveries
+}
+
+func (c *memoryConsumer) run() {
+	defer close(c.done)
+	defer close(c.deliveries)
+
+	for c.ctx.Err() == nil {
+		m, wakeup := c.q.pop(c.taskType)
+		if m == nil {
+			select {
+			case <-c.ctx.Done():
+			case <-wakeup:
+			}
+			continue
+		}
+
+		c.deliver(*m)
+	}
+}
+
+func (c *memoryConsumer) deliver(m memoryMessage) {
+	settled := make(chan struct{})
+	var once sync.Once
+
+	settle := func(op func()) {
+		once.Do(func() {
+			close(settled)
+			op()
+		})
+	}
+
+	d := Delivery{
+		Body:        m.body,
+		Redelivered: m.redelivered,
+		ack: func() error {
+			settle(func() {})
+			return nil
+		},
+		nack: func(requeue bool) error {
+			settle(func() {
+				if requeue {
+					c.q.requeue(m)
+				} else {
+					c.q.deadLetter(m.body)
+				}
+			})
+			return nil
+		},
+	}
+
+	select {
+	case c.deliveries <- d:
+	case <-c.ctx.Done():
+		d.Nack(true)
+		return
+	}
+
+	select {
+	case <-settled:
+	case <-c.ctx.Done():
+		d.Nack(true)
+	}
+}
+
+func (c *memoryConsumer) Close() error {
+	c.cancel()
+	<-c.done
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package queue
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+func newTestMemoryConsumer(t *testing.T, taskType string) (*MemoryQueue, Consumer) {
+	q := NewMemoryQueue()
+	t.Cleanup(func() { q.Close() })
+
+	c, err := q.NewConsumer(taskType)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+
+	return q, c
+}
+
+func deliveredTaskID(t *testing.T, d Delivery) uuid.UUID {
+	var delivered task.Task
+	require.NoError(t, json.Unmarshal(d.Body, &delivered))
+	return delivered.ID
+}
+
+func TestMemoryQueueSettle(t *testing.T) {
+	tests := []struct {
+		name     string
+		settle   func(d *Delivery) error
+		wantDead int
+	}{
+		{
+			name:     "Ack removes message",
+			settle:   func(d *Delivery) error { return d.Ack() },
+			wantDead: 0,
+		},
+		{
+			name:     "Nack without requeue moves message to dead letters",
+			settle:   func(d *Delivery) error { return d.Nack(false) },
+			wantDead: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, c := newTestMemoryConsumer(t, "send_email")
+
+			require.NoError(t, q.Publish(newTestTask("send_email", 0)))
+
+			d := receive(t, c, time.Second)
+			assert.False(t, d.Redelivered)
+			require.NoError(t, tt.settle(&d))
+
+			assert.Equal(t, 0, q.Len())
+			assert.Len(t, q.DeadLetters(), tt.wantDead)
+
+			select {
+			case <-c.Deliveries():
+				t.Fatal("settled message was delivered again")
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestMemoryQueueRequeue(t *testing.T) {
+	q, c := newTestMemoryConsumer(t, "send_email")
+
+	first := newTestTask("send_email", 0)
+	second := newTestTask("send_email", 0)
+	for _, err := range q.PublishBatch([]*task.Task{first, second}) {
+		require.NoError(t, err)
+	}
+
+	d := receive(t, c, time.Second)
+	assert.Equal(t, first.ID, deliveredTaskID(t, d))
+	assert.False(t, d.Redelivered)
+	require.NoError(t, d.Nack(true))
+
+	d = receive(t, c, time.Second)
+	assert.Equal(t, first.ID, deliveredTaskID(t, d))
+	assert.True(t, d.Redelivered)
+	require.NoError(t, d.Ack())
+
+	d = receive(t, c, time.Second)
+	assert.Equal(t, second.ID, deliveredTaskID(t, d))
+	assert.False(t, d.Redelivered)
+	require.NoError(t, d.Ack())
+
+	assert.Empty(t, q.DeadLetters())
+}
+
+func TestMemoryQueuePriority(t *testing.T) {
+	q := NewMemoryQueue()
+	defer q.Close()
+
+	low := newTestTask("send_email", 0)
+	high := newTestTask("send_email", 9)
+	medium := newTestTask("send_email", 5)
+	otherType := newTestTask("process_image", 9)
+	for _, err := range q.PublishBatch([]*task.Task{low, high, medium, otherType}) {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 4, q.Len())
+
+	c, err := q.NewConsumer("send_email")
+	require.NoError(t, err)
+	defer c.Close()
+
+	for _, want := range []uuid.UUID{high.ID, medium.ID, low.ID} {
+		d := receive(t, c, time.Second)
+		assert.Equal(t, want, deliveredTaskID(t, d))
+		require.NoError(t, d.Ack())
+	}
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestMemoryQueueClose(t *testing.T) {
+	q := NewMemoryQueue()
+
+	c, err := q.NewConsumer("send_email")
+	require.NoError(t, err)
+
+	require.NoError(t, q.Publish(newTestTask("send_email", 0)))
+	receive(t, c, time.Second)
+
+	require.NoError(t, c.Close())
+	assert.Equal(t, 1, q.Len())
+
+	_, err = q.NewConsumer("unknown")
+	assert.Error(t, err)
+
+	require.NoError(t, q.Close())
+	assert.Equal(t, ErrQueueClosed, q.Publish(newTestTask("send_email", 0)))
+	_, err = q.NewConsumer("send_email")
+	assert.Equal(t, ErrQueueClosed, err)
+}
@@ -0,0 +1,110 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type postgresConsumer struct {
+	q          *PostgresQueue
+	taskType   string
+	deliveries chan Delivery
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (c *postgresConsumer) Deliveries() <-chan Delivery {
+	return c.deliveries
+}
+
+func (c *postgresConsumer) run() {
+	defer close(c.done)
+	defer close(c.deliveries)
+
+	for c.ctx.Err() == nil {
+		wakeup := c.q.wakeupChan()
+
+		id, body, deliveries, err := c.q.claim(c.ctx, c.taskType)
+		if err != nil {
+			if err != pgx.ErrNoRows && c.ctx.Err() == nil {
+				c.q.logger.Error("failed to claim queue message", zap.Error(err))
+			}
+
+			select {
+			case <-c.ctx.Done():
+			case <-wakeup:
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+
+		c.deliver(id, body, deliveries > 1)
+	}
+}
+
+func (c *postgresConsumer) deliver(id int64, body []byte, redelivered bool) {
+	settled := make(chan struct{})
+	var once sync.Once
+
+	settle := func(op func() error) error {
+		var err error
+		once.Do(func() {
+			close(settled)
+			err = op()
+		})
+		return err
+	}
+
+	d := Delivery{
+		Body:        body,
+		Redelivered: redelivered,
+		ack: func() error {
+			return settle(func() error {
+				return c.q.ack(id)
+			})
+		},
+		nack: func(requeue bool) error {
+			return settle(func() error {
+				return c.q.nack(id, requeue)
+			})
+		},
+	}
+
+	select {
+	case c.deliveries <- d:
+	case <-c.ctx.Done():
+		if err := d.Nack(true); err != nil {
+			c.q.logger.Error("failed to return message to queue", zap.Error(err))
+		}
+		return
+	}
+
+	ticker := time.NewTicker(visibilityExtend)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-settled:
+			return
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.q.extend(id); err != nil {
+				c.q.logger.Error("failed to extend visibility of message", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (c *postgresConsumer) Close() error {
+	c.cancel()
+	<-c.done
+
+	return nil
+}
@@ -0,0 +1,300 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
+	"github.com/imightbuyaboat/TaskFlow/pkg/postgres"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	notifyChannel     = "queue_messages"
+	visibilityTimeout = 30 * time.Second
+	visibilityExtend  = 10 * time.Second
+	pollInterval      = 5 * time.Second
+)
+
+type PostgresQueue struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	wakeup chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewPostgresQueue(postgresURL string, logger *zap.Logger) (*PostgresQueue, error) {
+	pool, err := postgres.NewPoolDB(context.Background(), postgresURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q := &PostgresQueue{
+		pool:   pool,
+		logger: logger,
+		wakeup: make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go q.listen()
+
+	return q, nil
+}
+
+func (q *PostgresQueue) listen() {
+	defer close(q.done)
+
+	delay := minReconnectDelay
+	for {
+		err := q.waitNotifications(func() {
+			delay = minReconnectDelay
+		})
+		if q.ctx.Err() != nil {
+			return
+		}
+
+		q.logger.Error("failed to listen for queue notifications", zap.Error(err), zap.Duration("retry_in", delay))
+		q.broadcast()
+
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+func (q *PostgresQueue) waitNotifications(listening func()) error {
+	conn, err := q.pool.Acquire(q.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %v", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(q.ctx, "listen "+notifyChannel); err != nil {
+		return fmt.Errorf("failed to listen channel: %v", err)
+	}
+	listening()
+
+	for {
+		if _, err := conn.Conn().WaitForNotification(q.ctx); err != nil {
+			return fmt.Errorf("failed to wait for notification: %v", err)
+		}
+		q.broadcast()
+	}
+}
+
+func (q *PostgresQueue) broadcast() {
+	q.mu.Lock()
+	close(q.wakeup)
+	q.wakeup = make(chan struct{})
+	q.mu.Unlock()
+}
+
+func (q *PostgresQueue) wakeupChan() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.wakeup
+}
+
+func (q *PostgresQueue) Publish(t *task.Task) error {
+	return q.PublishBatch([]*task.Task{t})[0]
+}
+
+func (q *PostgresQueue) PublishBatch(tasks []*task.Task) []error {
+	errs := q.publishBatch(tasks)
+	for _, err := range errs {
+		if err != nil {
+			metrics.QueuePublishErrors.Inc()
+		}
+	}
+
+	return errs
+}
+
+func (q *PostgresQueue) publishBatch(tasks []*task.Task) []error {
+	errs := make([]error, len(tasks))
+
+	bodies := make([]string, 0, len(tasks))
+	priorities := make([]int16, 0, len(tasks))
+	types := make([]string, 0, len(tasks))
+	published := make([]int, 0, len(tasks))
+	for i, t := range tasks {
+		body, err := json.Marshal(t)
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to marshal task: %v", err)
+			continue
+		}
+		bodies = append(bodies, string(body))
+		priorities = append(priorities, int16(t.Priority))
+		types = append(types, t.Type)
+		published = append(published, i)
+	}
+
+	if len(bodies) == 0 {
+		return errs
+	}
+
+	if err := q.insertMessages(bodies, priorities, types); err != nil {
+		for _, i := range published {
+			errs[i] = err
+		}
+	}
+
+	return errs
+}
+
+func (q *PostgresQueue) insertMessages(bodies []string, priorities []int16, types []string) error {
+	tx, err := q.pool.Begin(q.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(q.ctx)
+
+	query := `insert into queue_messages (body, priority, task_type)
+		select * from unnest(@bodies::jsonb[], @priorities::smallint[], @types::text[])`
+	args := pgx.NamedArgs{
+		"bodies":     bodies,
+		"priorities": priorities,
+		"types":      types,
+	}
+
+	if _, err := tx.Exec(q.ctx, query, args); err != nil {
+		return fmt.Errorf("failed to publish tasks: %v", err)
+	}
+
+	if _, err := tx.Exec(q.ctx, "select pg_notify(@channel, '')", pgx.NamedArgs{"channel": notifyChannel}); err != nil {
+		return fmt.Errorf("failed to notify consumers: %v", err)
+	}
+
+	if err := tx.Commit(q.ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (q *PostgresQueue) claim(ctx context.Context, taskType string) (int64, []byte, int, error) {
+	query := `update queue_messages
+		set visible_at = now() + @timeout_ms * interval '1 millisecond', deliveries = deliveries + 1
+		where id = (
+			select id from queue_messages
+			where dead_at is null and task_type = @task_type and visible_at <= now()
+			order by priority desc, id
+			limit 1
+			for update skip locked
+		)
+		returning id, body, deliveries`
+	args := pgx.NamedArgs{
+		"task_type":  taskType,
+		"timeout_ms": visibilityTimeout.Milliseconds(),
+	}
+
+	var id int64
+	var body []byte
+	var deliveries int
+	if err := q.pool.QueryRow(ctx, query, args).Scan(&id, &body, &deliveries); err != nil {
+		return 0, nil, 0, err
+	}
+
+	return id, body, deliveries, nil
+}
+
+func (q *PostgresQueue) extend(id int64) error {
+	query := `update queue_messages
+		set visible_at = now() + @timeout_ms * interval '1 millisecond'
+		where id = @id and dead_at is null`
+	args := pgx.NamedArgs{
+		"id":         id,
+		"timeout_ms": visibilityTimeout.Milliseconds(),
+	}
+
+	if _, err := q.pool.Exec(context.Background(), query, args); err != nil {
+		return fmt.Errorf("failed to extend visibility timeout: %v", err)
+	}
+
+	return nil
+}
+
+func (q *PostgresQueue) ack(id int64) error {
+	query := `delete from queue_messages where id = @id`
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	if _, err := q.pool.Exec(context.Background(), query, args); err != nil {
+		return fmt.Errorf("failed to ack message: %v", err)
+	}
+
+	return nil
+}
+
+func (q *PostgresQueue) nack(id int64, requeue bool) error {
+	query := `update queue_messages set dead_at = now() where id = @id`
+	if requeue {
+		query = `update queue_messages set visible_at = now() where id = @id`
+	}
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	if _, err := q.pool.Exec(context.Background(), query, args); err != nil {
+		return fmt.Errorf("failed to nack message: %v", err)
+	}
+
+	if requeue {
+		q.broadcast()
+	}
+
+	return nil
+}
+
+func (q *PostgresQueue) NewConsumer(taskType string) (Consumer, error) {
+	if !task.ValidateType(taskType) {
+		return nil, fmt.Errorf("unknown type of task %q", taskType)
+	}
+
+	ctx, cancel := context.WithCancel(q.ctx)
+
+	c := &postgresConsumer{
+		q:          q,
+		taskType:   taskType,
+		deliveries: make(chan Delivery),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+
+	go c.run()
+
+	return c, nil
+}
+
+func (q *PostgresQueue) Check(ctx context.Context) error {
+	return q.pool.Ping(ctx)
+}
+
+func (q *PostgresQueue) Close() error {
+	q.cancel()
+	<-q.done
+	q.pool.Close()
+
+	return nil
+}
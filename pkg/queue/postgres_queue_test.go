@@ -0,0 +1,195 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/migrate"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+func newTestPostgresURL(t *testing.T) string {
+	postgresURL := os.Getenv("TEST_POSTGRES_URL")
+	if postgresURL == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("queue_test_%d", time.Now().UnixNano())
+
+	admin, err := pgxpool.New(ctx, postgresURL)
+	require.NoError(t, err)
+	t.Cleanup(admin.Close)
+
+	_, err = admin.Exec(ctx, "create schema "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin.Exec(context.Background(), "drop schema "+schema+" cascade")
+	})
+
+	u, err := url.Parse(postgresURL)
+	require.NoError(t, err)
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+
+	pool, err := pgxpool.New(ctx, u.String())
+	require.NoError(t, err)
+	defer pool.Close()
+
+	m, err := migrate.NewPostgresMigrator(pool)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	return u.String()
+}
+
+func newTestPostgresQueue(t *testing.T, postgresURL string) *PostgresQueue {
+	q, err := NewPostgresQueue(postgresURL, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { q.Close() })
+
+	return q
+}
+
+func newTestTask(taskType string, priority uint8) *task.Task {
+	return &task.Task{
+		ID:       uuid.New(),
+		UserID:   1,
+		Type:     taskType,
+		Payload:  map[string]interface{}{"to": "test@test.com"},
+		Priority: priority,
+	}
+}
+
+func receive(t *testing.T, c Consumer, timeout time.Duration) Delivery {
+	select {
+	case d := <-c.Deliveries():
+		return d
+	case <-time.After(timeout):
+		t.Fatal("message was not delivered")
+		return Delivery{}
+	}
+}
+
+func TestPostgresQueueClaim(t *testing.T) {
+	ctx := context.Background()
+	q := newTestPostgresQueue(t, newTestPostgresURL(t))
+
+	for _, err := range q.PublishBatch([]*task.Task{
+		newTestTask("send_email", 0),
+		newTestTask("send_email", 5),
+		newTestTask("process_image", 9),
+	}) {
+		require.NoError(t, err)
+	}
+
+	_, body, deliveries, err := q.claim(ctx, "send_email")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deliveries)
+	assert.Contains(t, string(body), `"priority":5`)
+
+	_, body, _, err = q.claim(ctx, "send_email")
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"priority":0`)
+
+	_, _, _, err = q.claim(ctx, "send_email")
+	assert.Equal(t, pgx.ErrNoRows, err)
+
+	_, body, _, err = q.claim(ctx, "process_image")
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"type":"process_image"`)
+}
+
+func TestPostgresQueueVisibilityTimeout(t *testing.T) {
+	ctx := context.Background()
+	q := newTestPostgresQueue(t, newTestPostgresURL(t))
+
+	require.NoError(t, q.Publish(newTestTask("send_email", 0)))
+
+	id, _, deliveries, err := q.claim(ctx, "send_email")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deliveries)
+
+	var hiddenMs int64
+	err = q.pool.QueryRow(ctx, "select (extract(epoch from visible_at - now()) * 1000)::bigint from queue_messages where id = $1", id).Scan(&hiddenMs)
+	require.NoError(t, err)
+	assert.InDelta(t, visibilityTimeout.Milliseconds(), hiddenMs, 1000)
+
+	_, _, _, err = q.claim(ctx, "send_email")
+	assert.Equal(t, pgx.ErrNoRows, err)
+
+	_, err = q.pool.Exec(ctx, "update queue_messages set visible_at = now() - interval '1 second' where id = $1", id)
+	require.NoError(t, err)
+
+	reclaimedID, _, deliveries, err := q.claim(ctx, "send_email")
+	require.NoError(t, err)
+	assert.Equal(t, id, reclaimedID)
+	assert.Equal(t, 2, deliveries)
+}
+
+func TestPostgresQueueAckNack(t *testing.T) {
+	ctx := context.Background()
+	q := newTestPostgresQueue(t, newTestPostgresURL(t))
+
+	c, err := q.NewConsumer("send_email")
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, q.Publish(newTestTask("send_email", 0)))
+
+	d := receive(t, c, time.Second)
+	assert.False(t, d.Redelivered)
+	require.NoError(t, d.Nack(true))
+
+	d = receive(t, c, time.Second)
+	assert.True(t, d.Redelivered)
+	require.NoError(t, d.Nack(false))
+
+	var dead int
+	err = q.pool.QueryRow(ctx, "select count(*) from queue_messages where dead_at is not null").Scan(&dead)
+	require.NoError(t, err)
+	assert.Equal(t, 1, dead)
+
+	require.NoError(t, q.Publish(newTestTask("send_email", 0)))
+
+	d = receive(t, c, time.Second)
+	assert.False(t, d.Redelivered)
+	require.NoError(t, d.Ack())
+
+	var pending int
+	err = q.pool.QueryRow(ctx, "select count(*) from queue_messages where dead_at is null").Scan(&pending)
+	require.NoError(t, err)
+	assert.Equal(t, 0, pending)
+}
+
+func TestPostgresQueueNotifyWakeup(t *testing.T) {
+	postgresURL := newTestPostgresURL(t)
+	consumerQueue := newTestPostgresQueue(t, postgresURL)
+	publisherQueue := newTestPostgresQueue(t, postgresURL)
+
+	c, err := consumerQueue.NewConsumer("send_email")
+	require.NoError(t, err)
+	defer c.Close()
+
+	time.Sleep(500 * time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, publisherQueue.Publish(newTestTask("send_email", 0)))
+
+	d := receive(t, c, pollInterval)
+	require.NoError(t, d.Ack())
+	assert.Less(t, time.Since(start), pollInterval/2)
+}
@@ -0,0 +1,41 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"go.uber.org/zap"
+)
+
+const (
+	BackendRabbitMQ = "rabbitmq"
+	BackendPostgres = "postgres"
+)
+
+type Queue interface {
+	Publish(t *task.Task) error
+	PublishBatch(tasks []*task.Task) []error
+	NewConsumer(taskType string) (Consumer, error)
+	Check(ctx context.Context) error
+	Close() error
+}
+
+func NewQueue(backend, amqpURL, postgresURL string, logger *zap.Logger) (Queue, error) {
+	switch backend {
+	case "", BackendRabbitMQ:
+		q, err := NewRabbitMQQueue(amqpURL, logger)
+		if err != nil {
+			return nil, err
+		}
+		return q, nil
+	case BackendPostgres:
+		q, err := NewPostgresQueue(postgresURL, logger)
+		if err != nil {
+			return nil, err
+		}
+		return q, nil
+	default:
+		return nil, fmt.Errorf("unknown queue backend %q", backend)
+	}
+}
@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+type rabbitMQConsumer struct {
+	q          *RabbitMQQueue
+	queue      string
+	deliveries chan Delivery
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	channel *amqp.Channel
+}
+
+func (c *rabbitMQConsumer) Deliveries() <-chan Delivery {
+	return c.deliveries
+}
+
+func (c *rabbitMQConsumer) subscribe() (<-chan amqp.Delivery, error) {
+	conn, err := c.q.waitConnected(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %v", err)
+	}
+
+	err = ch.Qos(
+		1,
+		0,
+		false,
+	)
+	if err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to set Qos: %v", err)
+	}
+
+	msgs, err := ch.Consume(
+		c.queue,
+		"",
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to register consumer: %v", err)
+	}
+
+	c.mu.Lock()
+	c.channel = ch
+	c.mu.Unlock()
+
+	return msgs, nil
+}
+
+func (c *rabbitMQConsumer) run(msgs <-chan amqp.Delivery) {
+	defer close(c.done)
+	defer close(c.deliveries)
+
+	for {
+		for d := range msgs {
+			delivery := Delivery{
+				Body:        d.Body,
+				Redelivered: d.Redelivered,
+				ack: func() error {
+					return d.Ack(false)
+				},
+				nack: func(requeue bool) error {
+					return d.Nack(false, requeue)
+				},
+			}
+
+			select {
+			case c.deliveries <- delivery:
+			case <-c.ctx.Done():
+				return
+			}
+		}
+
+		if c.ctx.Err() != nil {
+			return
+		}
+		c.q.logger.Error("consumer lost subscription to RabbitMQ")
+
+		delay := minReconnectDelay
+		for {
+			var err error
+			msgs, err = c.subscribe()
+			if err == nil {
+				c.q.logger.Info("consumer successfully resubscribe to RabbitMQ")
+				break
+			}
+			if c.ctx.Err() != nil || err == ErrQueueClosed {
+				return
+			}
+
+			c.q.logger.Error("failed to resubscribe consumer", zap.Error(err), zap.Duration("retry_in", delay))
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxReconnectDelay)
+		}
+	}
+}
+
+func (c *rabbitMQConsumer) Close() error {
+	c.cancel()
+
+	c.mu.Lock()
+	ch := c.channel
+	c.mu.Unlock()
+
+	var err error
+	if ch != nil {
+		if closeErr := ch.Close(); closeErr != nil && closeErr != amqp.ErrClosed {
+			err = fmt.Errorf("failed to close channel: %v", closeErr)
+		}
+	}
+
+	<-c.done
+	return err
+}
@@ -1,32 +1,116 @@
 package queue
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
 	"github.com/imightbuyaboat/TaskFlow/pkg/task"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+const (
+	tasksQueuePrefix      = "tasks."
+	deadLetterExchange    = "tasks.dlx"
+	deadLetterQueue       = "tasks.dead"
+	publishConfirmTimeout = 5 * time.Second
+	returnsBufferSize     = 64
+	minReconnectDelay     = time.Second
+	maxReconnectDelay     = 30 * time.Second
+)
+
+var (
+	ErrNotConnected = errors.New("not connected to RabbitMQ")
+	ErrQueueClosed  = errors.New("queue is closed")
+	ErrUnroutable   = errors.New("task was returned by broker as unroutable")
 )
 
 type RabbitMQQueue struct {
-	connection *amqp.Connection
-	channel    *amqp.Channel
-	queue      *amqp.Queue
+	url    string
+	logger *zap.Logger
+
+	mu          sync.RWMutex
+	connection  *amqp.Connection
+	channel     *amqp.Channel
+	returns     chan amqp.Return
+	reconnected chan struct{}
+
+	publishMu sync.Mutex
+
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+func NewRabbitMQQueue(amqpURL string, logger *zap.Logger) (*RabbitMQQueue, error) {
+	q := &RabbitMQQueue{
+		url:         amqpURL,
+		logger:      logger,
+		reconnected: make(chan struct{}),
+		closing:     make(chan struct{}),
+	}
+
+	if err := q.connect(); err != nil {
+		return nil, err
+	}
+
+	go q.watch()
+
+	return q, nil
 }
 
-func NewRabbitMQQueue(amqpURL string) (*RabbitMQQueue, error) {
-	conn, err := amqp.Dial(amqpURL)
+func (q *RabbitMQQueue) connect() error {
+	conn, err := amqp.Dial(q.url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect RabbitMQ: %v", err)
+		return fmt.Errorf("failed to connect RabbitMQ: %v", err)
 	}
 
+	ch, returns, err := openPublishChannel(conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	q.mu.Lock()
+	q.connection = conn
+	q.channel = ch
+	q.returns = returns
+	close(q.reconnected)
+	q.reconnected = make(chan struct{})
+	q.mu.Unlock()
+
+	return nil
+}
+
+func openPublishChannel(conn *amqp.Connection) (*amqp.Channel, chan amqp.Return, error) {
 	ch, err := conn.Channel()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open channel: %v", err)
+		return nil, nil, fmt.Errorf("failed to open channel: %v", err)
 	}
 
-	q, err := ch.QueueDeclare(
-		"tasks",
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, nil, fmt.Errorf("failed to put channel into confirm mode: %v", err)
+	}
+
+	if err := declareTopology(ch); err != nil {
+		ch.Close()
+		return nil, nil, err
+	}
+
+	returns := ch.NotifyReturn(make(chan amqp.Return, returnsBufferSize))
+
+	return ch, returns, nil
+}
+
+func declareTopology(ch *amqp.Channel) error {
+	err := ch.ExchangeDeclare(
+		deadLetterExchange,
+		"fanout",
 		true,
 		false,
 		false,
@@ -34,69 +118,343 @@ func NewRabbitMQQueue(amqpURL string) (*RabbitMQQueue, error) {
 		nil,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to declare queue: %v", err)
+		return fmt.Errorf("failed to declare dead-letter exchange: %v", err)
 	}
 
-	return &RabbitMQQueue{
-		connection: conn,
-		channel:    ch,
-		queue:      &q,
-	}, nil
-}
-
-func (q *RabbitMQQueue) Publish(t *task.Task) error {
-	body, err := json.Marshal(t)
+	dlq, err := ch.QueueDeclare(
+		deadLetterQueue,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to marshal task: %v", err)
+		return fmt.Errorf("failed to declare dead-letter queue: %v", err)
 	}
 
-	err = q.channel.Publish(
+	err = ch.QueueBind(
+		dlq.Name,
 		"",
-		q.queue.Name,
+		deadLetterExchange,
 		false,
-		false,
-		amqp.Publishing{
-			ContentType:  "application/json",
-			Body:         body,
-			DeliveryMode: amqp.Persistent,
-		},
+		nil,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to publish task: %v", err)
+		return fmt.Errorf("failed to bind dead-letter queue: %v", err)
+	}
+
+	for _, taskType := range task.Types() {
+		_, err = ch.QueueDeclare(
+			tasksQueueName(taskType),
+			true,
+			false,
+			false,
+			false,
+			amqp.Table{
+				"x-dead-letter-exchange": deadLetterExchange,
+				"x-max-priority":         task.MaxPriority,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare queue of %s tasks: %v", taskType, err)
+		}
 	}
 
 	return nil
 }
 
-func (q *RabbitMQQueue) NewConsumerChannel() (*amqp.Channel, <-chan amqp.Delivery, error) {
-	ch, err := q.connection.Channel()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open channel: %v", err)
+func tasksQueueName(taskType string) string {
+	return tasksQueuePrefix + taskType
+}
+
+func (q *RabbitMQQueue) watch() {
+	for {
+		q.mu.RLock()
+		conn, ch := q.connection, q.channel
+		q.mu.RUnlock()
+
+		connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+		chClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case <-q.closing:
+			return
+
+		case err := <-connClosed:
+			q.logger.Error("lost connection to RabbitMQ", zap.Error(amqpError(err)))
+			if !q.reconnect() {
+				return
+			}
+
+		case err := <-chClosed:
+			q.logger.Error("lost publishing channel to RabbitMQ", zap.Error(amqpError(err)))
+			if !q.reopenChannel(conn) && !q.reconnect() {
+				return
+			}
+		}
 	}
+}
 
-	err = ch.Qos(
-		1,
-		0,
-		false,
-	)
+func (q *RabbitMQQueue) reopenChannel(conn *amqp.Connection) bool {
+	if conn.IsClosed() {
+		return false
+	}
+
+	ch, returns, err := openPublishChannel(conn)
 	if err != nil {
-		ch.Close()
-		return nil, nil, fmt.Errorf("failed to set Qos: %v", err)
+		q.logger.Error("failed to reopen publishing channel", zap.Error(err))
+		conn.Close()
+		return false
 	}
 
-	msgs, err := ch.Consume(
-		q.queue.Name,
-		"",
-		false,
-		false,
-		false,
-		false,
-		nil,
-	)
+	q.mu.Lock()
+	q.channel = ch
+	q.returns = returns
+	q.mu.Unlock()
+
+	q.logger.Info("successfully reopen publishing channel to RabbitMQ")
+	return true
+}
+
+func (q *RabbitMQQueue) reconnect() bool {
+	q.mu.Lock()
+	if q.connection != nil {
+		q.connection.Close()
+	}
+	q.connection = nil
+	q.channel = nil
+	q.returns = nil
+	q.mu.Unlock()
+
+	delay := minReconnectDelay
+	for {
+		select {
+		case <-q.closing:
+			return false
+		case <-time.After(delay):
+		}
+
+		if err := q.connect(); err != nil {
+			q.logger.Error("failed to reconnect to RabbitMQ", zap.Error(err), zap.Duration("retry_in", delay))
+			delay = min(delay*2, maxReconnectDelay)
+			continue
+		}
+
+		q.logger.Info("successfully reconnect to RabbitMQ")
+		return true
+	}
+}
+
+func (q *RabbitMQQueue) waitConnected(ctx context.Context) (*amqp.Connection, error) {
+	for {
+		q.mu.RLock()
+		conn, reconnected := q.connection, q.reconnected
+		q.mu.RUnlock()
+
+		if conn != nil && !conn.IsClosed() {
+			return conn, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-q.closing:
+			return nil, ErrQueueClosed
+		case <-reconnected:
+		}
+	}
+}
+
+func (q *RabbitMQQueue) Publish(t *task.Task) error {
+	return q.PublishBatch([]*task.Task{t})[0]
+}
+
+func (q *RabbitMQQueue) PublishBatch(tasks []*task.Task) []error {
+	errs := q.publishBatch(tasks)
+	for _, err := range errs {
+		if err != nil {
+			metrics.QueuePublishErrors.Inc()
+		}
+	}
+
+	return errs
+}
+
+func (q *RabbitMQQueue) publishBatch(tasks []*task.Task) []error {
+	errs := make([]error, len(tasks))
+
+	q.publishMu.Lock()
+	defer q.publishMu.Unlock()
+
+	q.mu.RLock()
+	ch, returns := q.channel, q.returns
+	q.mu.RUnlock()
+
+	if ch == nil || ch.IsClosed() {
+		for i := range errs {
+			errs[i] = ErrNotConnected
+		}
+		return errs
+	}
+
+	drainReturns(returns, make(map[string]struct{}))
+	returned := make(map[string]struct{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishConfirmTimeout)
+	defer cancel()
+
+	confirmations := make([]*amqp.DeferredConfirmation, len(tasks))
+	for i, t := range tasks {
+		body, err := json.Marshal(t)
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to marshal task: %v", err)
+			continue
+		}
+
+		confirmations[i], err = ch.PublishWithDeferredConfirmWithContext(
+			ctx,
+			"",
+			tasksQueueName(t.Type),
+			true,
+			false,
+			amqp.Publishing{
+				ContentType:  "application/json",
+				Body:         body,
+				DeliveryMode: amqp.Persistent,
+				MessageId:    t.ID.String(),
+				Priority:     t.Priority,
+			},
+		)
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to publish task: %v", err)
+		}
+	}
+
+	for i, confirmation := range confirmations {
+		if confirmation == nil {
+			continue
+		}
+
+		if err := waitConfirmation(ctx, confirmation, returns, returned); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		if _, ok := returned[tasks[i].ID.String()]; ok {
+			errs[i] = ErrUnroutable
+		}
+	}
+
+	return errs
+}
+
+func waitConfirmation(ctx context.Context, confirmation *amqp.DeferredConfirmation, returns <-chan amqp.Return, returned map[string]struct{}) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to wait for publish confirmation: %v", ctx.Err())
+
+		case r, ok := <-returns:
+			if !ok {
+				returns = nil
+				continue
+			}
+			returned[r.MessageId] = struct{}{}
+
+		case <-confirmation.Done():
+			drainReturns(returns, returned)
+			if !confirmation.Acked() {
+				return fmt.Errorf("broker did not confirm publishing of task")
+			}
+			return nil
+		}
+	}
+}
+
+func drainReturns(returns <-chan amqp.Return, returned map[string]struct{}) {
+	for {
+		select {
+		case r, ok := <-returns:
+			if !ok {
+				return
+			}
+			returned[r.MessageId] = struct{}{}
+		default:
+			return
+		}
+	}
+}
+
+func (q *RabbitMQQueue) NewConsumer(taskType string) (Consumer, error) {
+	if !task.ValidateType(taskType) {
+		return nil, fmt.Errorf("unknown type of task %q", taskType)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &rabbitMQConsumer{
+		q:          q,
+		queue:      tasksQueueName(taskType),
+		deliveries: make(chan Delivery),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+
+	msgs, err := c.subscribe()
 	if err != nil {
-		ch.Close()
-		return nil, nil, fmt.Errorf("failed to register consumer: %v", err)
+		cancel()
+		return nil, err
+	}
+
+	go c.run(msgs)
+
+	return c, nil
+}
+
+func (q *RabbitMQQueue) Check(ctx context.Context) error {
+	q.mu.RLock()
+	conn, ch := q.connection, q.channel
+	q.mu.RUnlock()
+
+	if conn == nil || conn.IsClosed() {
+		return fmt.Errorf("connection is closed")
 	}
 
-	return ch, msgs, nil
+	if ch == nil || ch.IsClosed() {
+		return fmt.Errorf("channel is closed")
+	}
+
+	return nil
+}
+
+func (q *RabbitMQQueue) Close() error {
+	q.closeOnce.Do(func() {
+		close(q.closing)
+	})
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.channel != nil {
+		if err := q.channel.Close(); err != nil && err != amqp.ErrClosed {
+			return fmt.Errorf("failed to close channel: %v", err)
+		}
+	}
+
+	if q.connection != nil {
+		if err := q.connection.Close(); err != nil && err != amqp.ErrClosed {
+			return fmt.Errorf("failed to close connection: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func amqpError(err *amqp.Error) error {
+	if err == nil {
+		return amqp.ErrClosed
+	}
+	return err
 }
@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/robfig/cron/v3"
+)
+
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+type Schedule struct {
+	ID          uuid.UUID              `json:"id"`
+	UserID      uint64                 `json:"user_id"`
+	CronExpr    string                 `json:"cron_expr"`
+	Timezone    string                 `json:"timezone"`
+	Type        string                 `json:"type"`
+	Payload     map[string]interface{} `json:"payload"`
+	MaxRetries  uint8                  `json:"max_retries"`
+	RetryPolicy *task.RetryPolicy      `json:"retry_policy"`
+	NextRunAt   time.Time              `json:"next_run_at"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+type TemplateData struct {
+	ScheduleID  uuid.UUID
+	ScheduledAt time.Time
+}
+
+func NextRun(cronExpr, timezone string, after time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone: %v", err)
+	}
+
+	sched, err := parser.Parse(cronExpr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression: %v", err)
+	}
+
+	next := sched.Next(after.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression has no future occurrences")
+	}
+
+	return next.UTC(), nil
+}
+
+func RenderPayload(payload map[string]interface{}, data TemplateData) (map[string]interface{}, error) {
+	rendered, err := renderValue(payload, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return rendered.(map[string]interface{}), nil
+}
+
+func renderValue(value interface{}, data TemplateData) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		tmpl, err := template.New("payload").Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %q: %v", v, err)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to execute template %q: %v", v, err)
+		}
+		return buf.String(), nil
+
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			renderedItem, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = renderedItem
+		}
+		return rendered, nil
+
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			renderedItem, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = renderedItem
+		}
+		return rendered, nil
+
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/migrate"
+	msqlite "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const dsnPrefix = "sqlite://"
+
+func PathFromDSN(dsn string) (string, bool) {
+	return strings.CutPrefix(dsn, dsnPrefix)
+}
+
+func NewSQLiteDB(ctx context.Context, path string) (*sql.DB, error) {
+	db, err := Open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewSQLiteMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if _, err = m.Up(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate sqlite: %v", err)
+	}
+
+	return db, nil
+}
+
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite: %v", err)
+	}
+
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping sqlite: %v", err)
+	}
+
+	return db, nil
+}
+
+func IsUniqueViolation(err error) bool {
+	var sqliteErr *msqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
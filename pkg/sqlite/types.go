@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const timeLayout = "2006-01-02 15:04:05.000"
+
+func Time(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func NullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return Time(*t)
+}
+
+type JSON struct {
+	V interface{}
+}
+
+func (j JSON) Value() (driver.Value, error) {
+	b, err := json.Marshal(j.V)
+	if err != nil {
+		return nil, err
+	}
+	if string(b) == "null" {
+		return nil, nil
+	}
+
+	return string(b), nil
+}
+
+func (j JSON) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), j.V)
+	case []byte:
+		return json.Unmarshal(v, j.V)
+	default:
+		return fmt.Errorf("unsupported type of json value: %T", src)
+	}
+}
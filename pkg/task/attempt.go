@@ -0,0 +1,18 @@
+package task
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Attempt struct {
+	ID         uint64     `json:"id"`
+	TaskID     uuid.UUID  `json:"task_id"`
+	Attempt    uint8      `json:"attempt"`
+	WorkerID   string     `json:"worker_id"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	DurationMs *int64     `json:"duration_ms"`
+	Error      *string    `json:"error"`
+}
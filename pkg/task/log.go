@@ -0,0 +1,14 @@
+package task
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Log struct {
+	ID        uint64    `json:"id"`
+	TaskID    uuid.UUID `json:"task_id"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,175 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var referenceRegexp = regexp.MustCompile(`^\{\{\s*([^.\s{}]+)((?:\.[A-Za-z0-9_]+)+)\s*\}\}$`)
+
+type Reference struct {
+	Task string
+	Path []string
+}
+
+func (r Reference) String() string {
+	return "{{ " + r.Task + "." + strings.Join(r.Path, ".") + " }}"
+}
+
+func ParseReference(s string) (Reference, bool) {
+	m := referenceRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return Reference{}, false
+	}
+
+	return Reference{Task: m[1], Path: strings.Split(m[2][1:], ".")}, true
+}
+
+func References(payload map[string]interface{}) []Reference {
+	var refs []Reference
+	walkReferences(payload, func(ref Reference) (interface{}, error) {
+		refs = append(refs, ref)
+		return ref.String(), nil
+	})
+
+	return refs
+}
+
+func RenameReferences(payload map[string]interface{}, rename func(name string) (string, bool)) map[string]interface{} {
+	renamed, _ := walkReferences(payload, func(ref Reference) (interface{}, error) {
+		if task, ok := rename(ref.Task); ok {
+			ref.Task = task
+		}
+		return ref.String(), nil
+	})
+
+	return renamed.(map[string]interface{})
+}
+
+func ValidateReferences(typeOfTask string, payload map[string]interface{}, parentTypes map[string]string) error {
+	substituted, err := walkReferences(payload, func(ref Reference) (interface{}, error) {
+		parentType, ok := parentTypes[ref.Task]
+		if !ok {
+			return nil, fmt.Errorf("payload references task %q that is not in depends_on", ref.Task)
+		}
+
+		value, err := sampleValue(resultSamples[parentType], ref.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reference %s: %v", ref, err)
+		}
+
+		return value, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(substituted)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %v", err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal(data, newPayloadFunctions[typeOfTask]()); errors.As(err, &typeErr) {
+		return fmt.Errorf("reference in field %q of payload has wrong type", typeErr.Field)
+	}
+
+	return nil
+}
+
+func ResolveReferences(payload map[string]interface{}, results map[uuid.UUID]map[string]interface{}) (map[string]interface{}, error) {
+	resolved, err := walkReferences(payload, func(ref Reference) (interface{}, error) {
+		taskID, err := uuid.Parse(ref.Task)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task in reference %s", ref)
+		}
+
+		var value interface{} = results[taskID]
+		for _, segment := range ref.Path {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				item, ok := v[segment]
+				if !ok {
+					return nil, fmt.Errorf("result of task %s has no value %s", taskID, ref)
+				}
+				value = item
+			case []interface{}:
+				index, err := strconv.Atoi(segment)
+				if err != nil || index < 0 || index >= len(v) {
+					return nil, fmt.Errorf("result of task %s has no value %s", taskID, ref)
+				}
+				value = v[index]
+			default:
+				return nil, fmt.Errorf("result of task %s has no value %s", taskID, ref)
+			}
+		}
+
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resolved.(map[string]interface{}), nil
+}
+
+func sampleValue(sample map[string]interface{}, path []string) (interface{}, error) {
+	var value interface{} = sample
+	for _, segment := range path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			item, ok := v[segment]
+			if !ok {
+				return nil, fmt.Errorf("result has no field %q", segment)
+			}
+			value = item
+		case []interface{}:
+			if _, err := strconv.ParseUint(segment, 10, 0); err != nil {
+				return nil, fmt.Errorf("%q is not an index of list", segment)
+			}
+			value = v[0]
+		default:
+			return nil, fmt.Errorf("%q is not a field of result", segment)
+		}
+	}
+
+	return value, nil
+}
+
+func walkReferences(value interface{}, replace func(ref Reference) (interface{}, error)) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if ref, ok := ParseReference(v); ok {
+			return replace(ref)
+		}
+		return v, nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			replaced, err := walkReferences(item, replace)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = replaced
+		}
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, item := range v {
+			replaced, err := walkReferences(item, replace)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = replaced
+		}
+		return s, nil
+	default:
+		return v, nil
+	}
+}
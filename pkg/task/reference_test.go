@@ -0,0 +1,97 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantRef Reference
+		wantOk  bool
+	}{
+		{"field", "{{ resize.path }}", Reference{Task: "resize", Path: []string{"path"}}, true},
+		{"index", "{{download.paths.0}}", Reference{Task: "download", Path: []string{"paths", "0"}}, true},
+		{"plain string", "image.png", Reference{}, false},
+		{"without field", "{{ resize }}", Reference{}, false},
+		{"embedded", "see {{ resize.path }}", Reference{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, ok := ParseReference(tt.value)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantRef, ref)
+		})
+	}
+}
+
+func TestValidateReferences(t *testing.T) {
+	parentTypes := map[string]string{"download": "download_files", "resize": "process_image"}
+
+	tests := []struct {
+		name       string
+		typeOfTask string
+		payload    map[string]interface{}
+		wantErr    string
+	}{
+		{"indexed path", "process_image", map[string]interface{}{"path": "{{ download.paths.0 }}"}, ""},
+		{"path in list", "send_email", map[string]interface{}{"attached_files": []interface{}{"{{ resize.path }}"}}, ""},
+		{"list instead of string", "process_image", map[string]interface{}{"path": "{{ download.paths }}"}, `reference in field "path" of payload has wrong type`},
+		{"unknown field", "process_image", map[string]interface{}{"path": "{{ resize.paths.0 }}"}, `invalid reference {{ resize.paths.0 }}: result has no field "paths"`},
+		{"field of list", "process_image", map[string]interface{}{"path": "{{ download.paths.first }}"}, `invalid reference {{ download.paths.first }}: "first" is not an index of list`},
+		{"task outside of depends_on", "process_image", map[string]interface{}{"path": "{{ crop.path }}"}, `payload references task "crop" that is not in depends_on`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReferences(tt.typeOfTask, tt.payload, parentTypes)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestResolveReferences(t *testing.T) {
+	downloadID, resizeID := uuid.New(), uuid.New()
+	payload := map[string]interface{}{
+		"path":  "{{ download.paths.1 }}",
+		"files": []interface{}{"{{ resize.path }}", "logo.png"},
+	}
+
+	renamed := RenameReferences(payload, func(name string) (string, bool) {
+		switch name {
+		case "download":
+			return downloadID.String(), true
+		case "resize":
+			return resizeID.String(), true
+		}
+		return "", false
+	})
+	assert.ElementsMatch(t, []Reference{
+		{Task: downloadID.String(), Path: []string{"paths", "1"}},
+		{Task: resizeID.String(), Path: []string{"path"}},
+	}, References(renamed))
+	assert.Equal(t, "{{ download.paths.1 }}", payload["path"])
+
+	results := map[uuid.UUID]map[string]interface{}{
+		downloadID: {"paths": []interface{}{"a.png", "b.png"}},
+		resizeID:   {"path": "b_processed.png"},
+	}
+	resolved, err := ResolveReferences(renamed, results)
+	require.NoError(t, err)
+	assert.Equal(t, "b.png", resolved["path"])
+	assert.Equal(t, []interface{}{"b_processed.png", "logo.png"}, resolved["files"])
+
+	results[downloadID] = map[string]interface{}{"paths": []interface{}{"a.png"}}
+	_, err = ResolveReferences(renamed, results)
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package task
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const maxRetryDelayMs = 24 * 60 * 60 * 1000
+
+var DefaultRetryPolicy = RetryPolicy{
+	InitialDelayMs: 1000,
+	Multiplier:     2,
+	MaxDelayMs:     5 * 60 * 1000,
+	Jitter:         0.1,
+}
+
+type RetryPolicy struct {
+	InitialDelayMs uint32  `json:"initial_delay_ms"`
+	Multiplier     float64 `json:"multiplier"`
+	MaxDelayMs     uint32  `json:"max_delay_ms"`
+	Jitter         float64 `json:"jitter"`
+}
+
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	if p.InitialDelayMs == 0 {
+		p.InitialDelayMs = DefaultRetryPolicy.InitialDelayMs
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	if p.MaxDelayMs == 0 {
+		p.MaxDelayMs = DefaultRetryPolicy.MaxDelayMs
+	}
+	return p
+}
+
+func ValidateRetryPolicy(p RetryPolicy) error {
+	if p.Multiplier < 1 {
+		return fmt.Errorf("multiplier must be greater than or equal to 1")
+	}
+
+	if p.MaxDelayMs < p.InitialDelayMs {
+		return fmt.Errorf("max_delay_ms must be greater than or equal to initial_delay_ms")
+	}
+
+	if p.MaxDelayMs > maxRetryDelayMs {
+		return fmt.Errorf("max_delay_ms must be less than or equal to %d", maxRetryDelayMs)
+	}
+
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("jitter must be in the range [0, 1]")
+	}
+
+	return nil
+}
+
+func (p RetryPolicy) NextDelay(attempt uint8) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := float64(p.InitialDelayMs) * math.Pow(p.Multiplier, float64(attempt-1))
+	if delay > float64(p.MaxDelayMs) {
+		delay = float64(p.MaxDelayMs)
+	}
+
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay) * time.Millisecond
+}
@@ -6,15 +6,24 @@ import (
 	"github.com/google/uuid"
 )
 
+const MaxPriority = 9
+
 type Task struct {
-	ID         uuid.UUID              `json:"id"`
-	UserID     uint64                 `json:"user_id"`
-	Type       string                 `json:"type"`
-	Payload    map[string]interface{} `json:"payload"`
-	Status     string                 `json:"status"`
-	Retries    uint8                  `json:"retries"`
-	MaxRetries uint8                  `json:"max_retries"`
-	RunAt      *time.Time             `json:"run_at"`
-	CreatedAt  time.Time              `json:"created_at"`
-	UpdatedAt  time.Time              `json:"updated_at"`
+	ID           uuid.UUID              `json:"id"`
+	UserID       uint64                 `json:"user_id"`
+	Type         string                 `json:"type"`
+	Payload      map[string]interface{} `json:"payload"`
+	Status       string                 `json:"status"`
+	Retries      uint8                  `json:"retries"`
+	MaxRetries   uint8                  `json:"max_retries"`
+	RunAt        *time.Time             `json:"run_at"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+	RetryPolicy  *RetryPolicy           `json:"retry_policy"`
+	DependsOn    []uuid.UUID            `json:"depends_on,omitempty"`
+	Result       map[string]interface{} `json:"result,omitempty"`
+	Priority     uint8                  `json:"priority"`
+	UniqueKey    *string                `json:"unique_key,omitempty"`
+	UniqueUntil  *time.Time             `json:"unique_until,omitempty"`
+	UniqueActive *bool                  `json:"-"`
 }
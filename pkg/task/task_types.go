@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/mail"
+	"sort"
 )
 
 var validatePayloadsFunctions = map[string]func(map[string]interface{}) error{
@@ -12,6 +13,18 @@ var validatePayloadsFunctions = map[string]func(map[string]interface{}) error{
 	"download_files": validateFileDownloadingPayload,
 }
 
+var newPayloadFunctions = map[string]func() interface{}{
+	"send_email":     func() interface{} { return &SendEmailPayload{} },
+	"process_image":  func() interface{} { return &ImageProcessingPayload{} },
+	"download_files": func() interface{} { return &FileDownloadingPayload{} },
+}
+
+var resultSamples = map[string]map[string]interface{}{
+	"send_email":     {},
+	"process_image":  {"path": "image.png"},
+	"download_files": {"paths": []interface{}{"file.bin"}},
+}
+
 type SendEmailPayload struct {
 	To            string   `json:"to"`
 	Subject       string   `json:"subject"`
@@ -35,6 +48,15 @@ type FileDownloadingPayload struct {
 	URLs []string `json:"urls"`
 }
 
+func Types() []string {
+	types := make([]string, 0, len(validatePayloadsFunctions))
+	for typeOfTask := range validatePayloadsFunctions {
+		types = append(types, typeOfTask)
+	}
+	sort.Strings(types)
+	return types
+}
+
 func ValidateType(typeOfTask string) bool {
 	_, ok := validatePayloadsFunctions[typeOfTask]
 	return ok
@@ -0,0 +1,14 @@
+package task
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Workflow struct {
+	ID        uuid.UUID       `json:"id"`
+	UserID    uint64          `json:"user_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Tasks     map[string]Task `json:"tasks"`
+}
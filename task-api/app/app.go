@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imightbuyaboat/TaskFlow/pkg/health"
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/auth"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/handler"
+	"go.uber.org/zap"
+)
+
+const shutdownTimeout = 30 * time.Second
+
+type Config struct {
+	Addr         string
+	DatabaseURL  string
+	Migrate      bool
+	HealthPrefix string
+}
+
+type App struct {
+	server *http.Server
+	db     database
+	logger *zap.Logger
+}
+
+func New(cfg Config, checker *health.Checker, logger *zap.Logger) (*App, error) {
+	if cfg.Migrate {
+		if err := migrateUp(context.Background(), cfg.DatabaseURL, logger); err != nil {
+			return nil, fmt.Errorf("failed to migrate db: %v", err)
+		}
+	}
+
+	db, err := openDB(cfg.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to db: %v", err)
+	}
+
+	tm := auth.NewJWTManager()
+
+	h, err := handler.NewHandler(db, tm, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create handler: %v", err)
+	}
+
+	checker.AddReadiness(cfg.HealthPrefix+"db", db.Ping)
+
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(h.MetricsMiddleware())
+
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	r.GET("/healthz", gin.WrapH(checker.LivenessHandler()))
+	r.GET("/readyz", gin.WrapH(checker.ReadinessHandler()))
+
+	r.POST("/api/register", h.RegisterHandler)
+	r.POST("/api/login", h.LoginHandler)
+
+	auth := r.Group("/api")
+	auth.Use(h.AuthMiddleware())
+	auth.POST("/tasks", h.CreateTaskHandler)
+	auth.POST("/tasks/batch", h.CreateTasksBatchHandler)
+	auth.GET("/tasks/:id", h.GetTaskHandler)
+	auth.GET("/tasks/:id/attempts", h.GetTaskAttemptsHandler)
+	auth.GET("/tasks/:id/logs", h.GetTaskLogsHandler)
+	auth.GET("/tasks", h.GetAllTasksHandler)
+	auth.DELETE("/tasks/:id", h.CancelTaskHandler)
+	auth.POST("/tasks/:id/retry", h.RetryTaskHandler)
+	auth.POST("/workflows", h.CreateWorkflowHandler)
+	auth.GET("/workflows/:id", h.GetWorkflowHandler)
+	auth.POST("/schedules", h.CreateScheduleHandler)
+	auth.GET("/schedules", h.GetAllSchedulesHandler)
+	auth.GET("/schedules/:id", h.GetScheduleHandler)
+	auth.DELETE("/schedules/:id", h.DeleteScheduleHandler)
+
+	return &App{
+		server: &http.Server{
+			Addr:    cfg.Addr,
+			Handler: r,
+		},
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+func (a *App) Run(ctx context.Context) error {
+	defer a.db.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+	}()
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("failed to start server: %v", err)
+	case <-ctx.Done():
+	}
+
+	a.logger.Info("shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := a.server.Shutdown(shutdownCtx); err != nil {
+		a.logger.Error("failed to gracefully shutdown server", zap.Error(err))
+	}
+
+	a.logger.Info("server gracefully stopped")
+	return nil
+}
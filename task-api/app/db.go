@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"io"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/migrate"
+	"github.com/imightbuyaboat/TaskFlow/pkg/postgres"
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/db"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/handler"
+	"go.uber.org/zap"
+)
+
+type database interface {
+	handler.DB
+	Ping(ctx context.Context) error
+	Close()
+}
+
+func openDB(databaseURL string) (database, error) {
+	if path, ok := sqlite.PathFromDSN(databaseURL); ok {
+		db, err := db.NewSQLiteDB(path)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+
+	db, err := db.NewPostgresDB(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func openMigrator(ctx context.Context, databaseURL string) (*migrate.Migrator, func(), error) {
+	if path, ok := sqlite.PathFromDSN(databaseURL); ok {
+		db, err := sqlite.Open(ctx, path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		m, err := migrate.NewSQLiteMigrator(db)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		return m, func() { db.Close() }, nil
+	}
+
+	pool, err := postgres.NewPoolDB(ctx, databaseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := migrate.NewPostgresMigrator(pool)
+	if err != nil {
+		pool.Close()
+		return nil, nil, err
+	}
+	return m, pool.Close, nil
+}
+
+func Migrate(ctx context.Context, databaseURL string, args []string, w io.Writer) error {
+	m, closeDB, err := openMigrator(ctx, databaseURL)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	return migrate.RunCommand(ctx, m, args, w)
+}
+
+func migrateUp(ctx context.Context, databaseURL string, logger *zap.Logger) error {
+	m, closeDB, err := openMigrator(ctx, databaseURL)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	applied, err := m.Up(ctx)
+	for _, migration := range applied {
+		logger.Info("applied migration", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+	}
+
+	return err
+}
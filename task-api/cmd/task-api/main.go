@@ -1,17 +1,15 @@
 package main
 
 import (
-	"fmt"
-	"net/http"
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/gin-gonic/gin"
+	"github.com/imightbuyaboat/TaskFlow/pkg/config"
+	"github.com/imightbuyaboat/TaskFlow/pkg/health"
 	"github.com/imightbuyaboat/TaskFlow/pkg/logger"
-	"github.com/imightbuyaboat/TaskFlow/pkg/queue"
-	"github.com/imightbuyaboat/TaskFlow/task-api/internal/auth"
-	"github.com/imightbuyaboat/TaskFlow/task-api/internal/db"
-	"github.com/imightbuyaboat/TaskFlow/task-api/internal/handler"
-	"github.com/joho/godotenv"
+	"github.com/imightbuyaboat/TaskFlow/task-api/app"
 	"go.uber.org/zap"
 )
 
@@ -19,48 +17,40 @@ func main() {
 	logger.InitLogger()
 	log := logger.GetLogger()
 
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("failed to load .env file", zap.Error(err))
-	}
-
-	postgresURL := fmt.Sprintf("postgres://%s:%s@db:%s/%s",
-		os.Getenv("POSTGRES_USER"), os.Getenv("POSTGRES_PASSWORD"),
-		os.Getenv("POSTGRES_PORT"), os.Getenv("POSTGRES_DB"))
-
-	db, err := db.NewPostgresDB(postgresURL)
+	cfg := config.Default()
+	args, err := cfg.Load(os.Args[0], os.Args[1:])
 	if err != nil {
-		log.Fatal("failed to create PostgresDB connection", zap.Error(err))
+		log.Fatal("failed to load config", zap.Error(err))
 	}
 
-	amqpURL := fmt.Sprintf("amqp://%s:%s@rabbitmq:%s/",
-		os.Getenv("AMQP_USER"), os.Getenv("AMQP_PASSWORD"),
-		os.Getenv("AMQP_PORT"))
+	if err := logger.SetLevel(cfg.Log.Level); err != nil {
+		log.Fatal("failed to set log level", zap.Error(err))
+	}
 
-	queue, err := queue.NewRabbitMQQueue(amqpURL)
-	if err != nil {
-		log.Fatal("failed to create RabbitMQ connection", zap.Error(err))
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := app.Migrate(context.Background(), cfg.DatabaseURL(), args[1:], os.Stdout); err != nil {
+			log.Fatal("failed to migrate db", zap.Error(err))
+		}
+		return
 	}
 
-	tm := auth.NewJWTManager()
+	log.Info("loaded config", zap.Any("config", cfg.Redacted()))
+
+	checker := health.NewChecker()
 
-	h, err := handler.NewHandler(db, queue, tm, log)
+	a, err := app.New(app.Config{
+		Addr:        cfg.API.Addr,
+		DatabaseURL: cfg.DatabaseURL(),
+		Migrate:     cfg.API.MigrateOnStart,
+	}, checker, log)
 	if err != nil {
-		log.Fatal("failed to create handler", zap.Error(err))
+		log.Fatal("failed to create api", zap.Error(err))
 	}
 
-	r := gin.New()
-	r.Use(gin.Recovery())
-
-	r.POST("/api/register", h.RegisterHandler)
-	r.POST("/api/login", h.LoginHandler)
-
-	auth := r.Group("/api")
-	auth.Use(h.AuthMiddleware())
-	auth.POST("/tasks", h.CreateTaskHandler)
-	auth.GET("/tasks/:id", h.GetTaskHandler)
-	auth.GET("/tasks", h.GetAllTasksHandler)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	if err = http.ListenAndServe(":8080", r); err != nil {
-		log.Fatal("failed to start server", zap.Error(err))
+	if err := a.Run(ctx); err != nil {
+		log.Fatal("failed to run api", zap.Error(err))
 	}
 }
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+func (db *PostgresDB) CreateTasks(tasks []*task.Task) ([]*task.Task, error) {
+	tx, err := db.Begin(db.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(db.ctx)
+
+	batch := &pgx.Batch{}
+	for _, t := range tasks {
+		status := "queued"
+		if t.RunAt != nil {
+			status = "postponed"
+		}
+
+		query, args := insertTaskQuery(t, status)
+		batch.Queue(query, args)
+	}
+
+	results := tx.SendBatch(db.ctx, batch)
+
+	createdTasks := make([]*task.Task, 0, len(tasks))
+	queued := make([]uuid.UUID, 0, len(tasks))
+	for range tasks {
+		createdTask, err := scanTask(results.QueryRow())
+		if err != nil {
+			results.Close()
+			return nil, fmt.Errorf("failed to insert task into db: %v", err)
+		}
+
+		createdTasks = append(createdTasks, createdTask)
+		if createdTask.Status == "queued" {
+			queued = append(queued, createdTask.ID)
+		}
+	}
+
+	if err := results.Close(); err != nil {
+		return nil, fmt.Errorf("failed to insert tasks into db: %v", err)
+	}
+
+	if len(queued) > 0 {
+		query := "insert into outbox (task_id) select unnest(@task_ids::uuid[])"
+		args := pgx.NamedArgs{
+			"task_ids": queued,
+		}
+
+		if _, err := tx.Exec(db.ctx, query, args); err != nil {
+			return nil, fmt.Errorf("failed to insert tasks into outbox: %v", err)
+		}
+	}
+
+	if err := tx.Commit(db.ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return createdTasks, nil
+}
@@ -3,7 +3,12 @@ package db
 import "errors"
 
 var (
-	ErrUserAlreadyExist  = errors.New("user already exist")
-	ErrIncorrectPassword = errors.New("incorrect password")
-	ErrNoRows            = errors.New("no rows selected")
+	ErrUserAlreadyExist   = errors.New("user already exist")
+	ErrIncorrectPassword  = errors.New("incorrect password")
+	ErrNoRows             = errors.New("no rows selected")
+	ErrTaskNotCancellable = errors.New("task cannot be cancelled")
+	ErrTaskNotRetryable   = errors.New("task cannot be retried")
+	ErrDependencyNotFound = errors.New("dependency of task not found")
+
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key was used with different request")
 )
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+type IdempotencyKey struct {
+	Key         string
+	Fingerprint string
+	Retention   time.Duration
+}
+
+func responseStatusCode(t, createdTask *task.Task) int {
+	if createdTask.ID != t.ID {
+		return http.StatusOK
+	}
+	return http.StatusCreated
+}
+
+func (db *PostgresDB) CreateTaskIdempotent(t *task.Task, key IdempotencyKey) (*task.Task, int, bool, error) {
+	tx, err := db.Begin(db.ctx)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(db.ctx)
+
+	query := `delete from idempotency_keys
+		where user_id = @user_id and key = @key and created_at < now() - @retention_ms * interval '1 millisecond'`
+	args := pgx.NamedArgs{
+		"user_id":      t.UserID,
+		"key":          key.Key,
+		"fingerprint":  key.Fingerprint,
+		"retention_ms": key.Retention.Milliseconds(),
+	}
+
+	if _, err := tx.Exec(db.ctx, query, args); err != nil {
+		return nil, 0, false, fmt.Errorf("failed to delete expired idempotency key: %v", err)
+	}
+
+	query = `insert into idempotency_keys (user_id, key, fingerprint) values (@user_id, @key, @fingerprint)
+		on conflict (user_id, key) do nothing`
+
+	tag, err := tx.Exec(db.ctx, query, args)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to insert idempotency key into db: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		replayedTask, statusCode, err := db.replayIdempotencyKey(tx, t.UserID, key)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		return replayedTask, statusCode, true, nil
+	}
+
+	createdTask, err := db.createTask(tx, t)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	statusCode := responseStatusCode(t, createdTask)
+
+	query = `update idempotency_keys set task_id = @task_id, response = @response, status_code = @status_code
+		where user_id = @user_id and key = @key`
+	args = pgx.NamedArgs{
+		"user_id":     t.UserID,
+		"key":         key.Key,
+		"task_id":     createdTask.ID,
+		"response":    createdTask,
+		"status_code": statusCode,
+	}
+
+	if _, err := tx.Exec(db.ctx, query, args); err != nil {
+		return nil, 0, false, fmt.Errorf("failed to save response of idempotent request: %v", err)
+	}
+
+	if err := tx.Commit(db.ctx); err != nil {
+		return nil, 0, false, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return createdTask, statusCode, false, nil
+}
+
+func (db *PostgresDB) replayIdempotencyKey(tx pgx.Tx, userID uint64, key IdempotencyKey) (*task.Task, int, error) {
+	query := "select fingerprint, response, status_code from idempotency_keys where user_id = @user_id and key = @key"
+	args := pgx.NamedArgs{
+		"user_id": userID,
+		"key":     key.Key,
+	}
+
+	var fingerprint string
+	var response *task.Task
+	var statusCode int
+	if err := tx.QueryRow(db.ctx, query, args).Scan(&fingerprint, &response, &statusCode); err != nil {
+		return nil, 0, fmt.Errorf("failed to select idempotency key from db: %v", err)
+	}
+
+	if fingerprint != key.Fingerprint {
+		return nil, 0, ErrIdempotencyKeyMismatch
+	}
+	if response == nil {
+		return nil, 0, fmt.Errorf("response of idempotent request is not saved")
+	}
+
+	return response, statusCode, nil
+}
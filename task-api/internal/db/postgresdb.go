@@ -33,37 +33,252 @@ func NewPostgresDB(postgresURL string) (*PostgresDB, error) {
 }
 
 func (db *PostgresDB) CreateTask(t *task.Task) (*task.Task, error) {
-	query := "insert into tasks	(id, user_id, type, payload, max_retries"
-	values := "values (@id, @user_id, @type, @payload, @max_retries"
+	tx, err := db.Begin(db.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(db.ctx)
+
+	createdTask, err := db.createTask(tx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(db.ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return createdTask, nil
+}
+
+func (db *PostgresDB) createTask(tx pgx.Tx, t *task.Task) (*task.Task, error) {
+	status := "queued"
+	if t.RunAt != nil {
+		status = "postponed"
+	}
+
+	if len(t.DependsOn) > 0 {
+		dependenciesStatus, err := db.lockDependencies(tx, t.UserID, t.DependsOn)
+		if err != nil {
+			return nil, err
+		}
+		if dependenciesStatus != "" {
+			status = dependenciesStatus
+		}
+	}
+
+	if t.UniqueKey != nil {
+		if err := db.releaseUniqueKey(tx, t.UserID, *t.UniqueKey); err != nil {
+			return nil, err
+		}
+	}
+
+	query, args := insertTaskQuery(t, status)
+	createdTask, err := scanTask(tx.QueryRow(db.ctx, query, args))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) && t.UniqueKey != nil {
+			return db.selectUniqueTask(tx, t.UserID, *t.UniqueKey)
+		}
+		return nil, fmt.Errorf("failed to insert task into db: %v", err)
+	}
+
+	if len(t.DependsOn) > 0 {
+		query = "insert into task_dependencies (task_id, depends_on) select @task_id, unnest(@depends_on::uuid[])"
+		args = pgx.NamedArgs{
+			"task_id":    t.ID,
+			"depends_on": t.DependsOn,
+		}
+
+		if _, err := tx.Exec(db.ctx, query, args); err != nil {
+			return nil, fmt.Errorf("failed to insert dependencies of task into db: %v", err)
+		}
+		createdTask.DependsOn = t.DependsOn
+	}
+
+	if createdTask.Status == "queued" {
+		if err := db.enqueueTask(tx, createdTask.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return createdTask, nil
+}
+
+func insertTaskQuery(t *task.Task, status string) (string, pgx.NamedArgs) {
+	query := "insert into tasks	(id, user_id, type, payload, status, max_retries, retry_policy, priority, unique_key, unique_until"
+	values := "values (@id, @user_id, @type, @payload, @status, @max_retries, @retry_policy, @priority, @unique_key, @unique_until"
 
 	args := pgx.NamedArgs{
-		"id":          t.ID,
-		"user_id":     t.UserID,
-		"type":        t.Type,
-		"payload":     t.Payload,
-		"max_retries": t.MaxRetries,
+		"id":           t.ID,
+		"user_id":      t.UserID,
+		"type":         t.Type,
+		"payload":      t.Payload,
+		"status":       status,
+		"max_retries":  t.MaxRetries,
+		"retry_policy": t.RetryPolicy,
+		"priority":     t.Priority,
+		"unique_key":   t.UniqueKey,
+		"unique_until": t.UniqueUntil,
 	}
 
 	if t.RunAt != nil {
 		args["run_at"] = t.RunAt
-		args["status"] = "postponed"
-		query += ", run_at, status"
-		values += ", @run_at, @status"
+		query += ", run_at"
+		values += ", @run_at"
 	}
 
-	query += ") " + values + ") returning *"
+	if t.UniqueKey != nil {
+		query += ", unique_active"
+		values += ", true"
+	}
 
-	var createdTask task.Task
-	err := db.QueryRow(db.ctx, query, args).Scan(
-		&createdTask.ID, &createdTask.UserID, &createdTask.Type, &createdTask.Payload,
-		&createdTask.Status, &createdTask.Retries, &createdTask.MaxRetries,
-		&createdTask.RunAt, &createdTask.CreatedAt, &createdTask.UpdatedAt,
-	)
+	query += ") " + values + ")"
+	if t.UniqueKey != nil {
+		query += " on conflict (user_id, unique_key) where unique_active and " + activeStatuses + " do nothing"
+	}
+	query += " returning *"
+
+	return query, args
+}
+
+func (db *PostgresDB) releaseUniqueKey(tx pgx.Tx, userID uint64, uniqueKey string) error {
+	query := `update tasks set unique_active = null
+	where user_id = @user_id and unique_key = @unique_key and unique_active and unique_until <= now()`
+	args := pgx.NamedArgs{
+		"user_id":    userID,
+		"unique_key": uniqueKey,
+	}
+
+	if _, err := tx.Exec(db.ctx, query, args); err != nil {
+		return fmt.Errorf("failed to release expired unique key: %v", err)
+	}
+
+	return nil
+}
+
+func (db *PostgresDB) selectUniqueTask(tx pgx.Tx, userID uint64, uniqueKey string) (*task.Task, error) {
+	query := "select * from tasks where user_id = @user_id and unique_key = @unique_key and unique_active and " + activeStatuses
+	args := pgx.NamedArgs{
+		"user_id":    userID,
+		"unique_key": uniqueKey,
+	}
+
+	t, err := scanTask(tx.QueryRow(db.ctx, query, args))
 	if err != nil {
-		return nil, fmt.Errorf("failed to insert task into db: %v", err)
+		return nil, fmt.Errorf("failed to select task with same unique key: %v", err)
 	}
 
-	return &createdTask, nil
+	return t, nil
+}
+
+func (db *PostgresDB) enqueueTask(tx pgx.Tx, taskID uuid.UUID) error {
+	query := "insert into outbox (task_id) values (@task_id)"
+	args := pgx.NamedArgs{
+		"task_id": taskID,
+	}
+
+	if _, err := tx.Exec(db.ctx, query, args); err != nil {
+		return fmt.Errorf("failed to insert task into outbox: %v", err)
+	}
+
+	return nil
+}
+
+func (db *PostgresDB) lockDependencies(tx pgx.Tx, userID uint64, dependsOn []uuid.UUID) (string, error) {
+	query := "select status from tasks where id = any(@depends_on) and user_id = @user_id for share"
+	args := pgx.NamedArgs{
+		"depends_on": dependsOn,
+		"user_id":    userID,
+	}
+
+	rows, err := tx.Query(db.ctx, query, args)
+	if err != nil {
+		return "", fmt.Errorf("failed to select dependencies of task: %v", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	status := ""
+	for rows.Next() {
+		var parentStatus string
+		if err := rows.Scan(&parentStatus); err != nil {
+			return "", fmt.Errorf("failed to scan dependencies of task: %v", err)
+		}
+		count++
+
+		switch parentStatus {
+		case "done":
+		case "failed", "cancelled", "skipped":
+			status = "skipped"
+		default:
+			if status == "" {
+				status = "waiting"
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to select dependencies of task: %v", err)
+	}
+
+	if count != len(dependsOn) {
+		return "", ErrDependencyNotFound
+	}
+
+	return status, nil
+}
+
+func (db *PostgresDB) skipDependents(tx pgx.Tx, taskID uuid.UUID) error {
+	query := `with recursive dependents as (
+		select task_id from task_dependencies where depends_on = @task_id
+		union
+		select d.task_id from task_dependencies d join dependents p on d.depends_on = p.task_id
+	)
+	update tasks set status = 'skipped', updated_at = now()
+	where id in (select task_id from dependents) and status = 'waiting'`
+	args := pgx.NamedArgs{
+		"task_id": taskID,
+	}
+
+	if _, err := tx.Exec(db.ctx, query, args); err != nil {
+		return fmt.Errorf("failed to skip dependents of task: %v", err)
+	}
+
+	return nil
+}
+
+func (db *PostgresDB) unskipDependents(tx pgx.Tx, taskID uuid.UUID) error {
+	query := `update tasks set status = 'waiting', updated_at = now()
+	where status = 'skipped'
+	and id in (select task_id from task_dependencies where depends_on = any(@task_ids))
+	and not exists (
+		select 1 from task_dependencies d join tasks p on p.id = d.depends_on
+		where d.task_id = tasks.id and p.status in ('failed', 'cancelled', 'skipped')
+	)
+	returning id`
+
+	taskIDs := []uuid.UUID{taskID}
+	for len(taskIDs) > 0 {
+		rows, err := tx.Query(db.ctx, query, pgx.NamedArgs{"task_ids": taskIDs})
+		if err != nil {
+			return fmt.Errorf("failed to unskip dependents of task: %v", err)
+		}
+
+		taskIDs = nil
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan dependents of task: %v", err)
+			}
+			taskIDs = append(taskIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to unskip dependents of task: %v", err)
+		}
+	}
+
+	return nil
 }
 
 func (db *PostgresDB) GetTask(userID uint64, taskID uuid.UUID) (*task.Task, error) {
@@ -73,12 +288,7 @@ func (db *PostgresDB) GetTask(userID uint64, taskID uuid.UUID) (*task.Task, erro
 		"user_id": userID,
 	}
 
-	var t task.Task
-	err := db.QueryRow(db.ctx, query, args).Scan(
-		&t.ID, &t.UserID, &t.Type, &t.Payload,
-		&t.Status, &t.Retries, &t.MaxRetries,
-		&t.RunAt, &t.CreatedAt, &t.UpdatedAt,
-	)
+	t, err := scanTask(db.QueryRow(db.ctx, query, args))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, ErrNoRows
@@ -86,37 +296,223 @@ func (db *PostgresDB) GetTask(userID uint64, taskID uuid.UUID) (*task.Task, erro
 		return nil, fmt.Errorf("failed to select task from db: %v", err)
 	}
 
-	return &t, nil
+	return t, nil
+}
+
+func (db *PostgresDB) CancelTask(userID uint64, taskID uuid.UUID) (*task.Task, error) {
+	tx, err := db.Begin(db.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(db.ctx)
+
+	query := `update tasks set status = 'cancelled', updated_at = now()
+	where id = @task_id and user_id = @user_id
+	and status in ('queued', 'postponed', 'processing', 'waiting')
+	returning *`
+	args := pgx.NamedArgs{
+		"task_id": taskID,
+		"user_id": userID,
+	}
+
+	t, err := scanTask(tx.QueryRow(db.ctx, query, args))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			if _, err := db.GetTask(userID, taskID); err != nil {
+				return nil, err
+			}
+			return nil, ErrTaskNotCancellable
+		}
+		return nil, fmt.Errorf("failed to cancel task: %v", err)
+	}
+
+	if err := db.skipDependents(tx, taskID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(db.ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return t, nil
 }
 
-func (db *PostgresDB) GetAllTasks(userID uint64) ([]task.Task, error) {
+func (db *PostgresDB) GetAllTasks(userID uint64, filter TaskFilter) ([]task.Task, error) {
 	query := "select * from tasks where user_id = @user_id"
 	args := pgx.NamedArgs{
 		"user_id": userID,
+		"limit":   filter.Limit,
+	}
+
+	if len(filter.Statuses) > 0 {
+		query += " and status = any(@statuses)"
+		args["statuses"] = filter.Statuses
+	}
+	if len(filter.Types) > 0 {
+		query += " and type = any(@types)"
+		args["types"] = filter.Types
+	}
+	if filter.CreatedAfter != nil {
+		query += " and created_at >= @created_after"
+		args["created_after"] = filter.CreatedAfter
+	}
+	if filter.CreatedBefore != nil {
+		query += " and created_at < @created_before"
+		args["created_before"] = filter.CreatedBefore
+	}
+	if filter.RunAfter != nil {
+		query += " and run_at >= @run_after"
+		args["run_after"] = filter.RunAfter
+	}
+	if filter.RunBefore != nil {
+		query += " and run_at < @run_before"
+		args["run_before"] = filter.RunBefore
+	}
+
+	sortColumn := "created_at"
+	if filter.SortBy == "run_at" {
+		sortColumn = "run_at"
+	}
+
+	direction, comparison := "asc", ">"
+	if filter.Desc {
+		direction, comparison = "desc", "<"
+	}
+
+	if filter.After != nil {
+		query += fmt.Sprintf(" and (%s, id) %s (@cursor_value, @cursor_id)", sortColumn, comparison)
+		args["cursor_value"] = filter.After.Value
+		args["cursor_id"] = filter.After.ID
 	}
 
+	query += fmt.Sprintf(" order by %s %s, id %s limit @limit", sortColumn, direction, direction)
+
 	rows, err := db.Query(db.ctx, query, args)
 	if err != nil {
 		return nil, fmt.Errorf("failed to select tasks from db: %v", err)
 	}
+	defer rows.Close()
 
-	var tasks []task.Task
+	tasks := []task.Task{}
 	for rows.Next() {
-		t := task.Task{}
-		err := rows.Scan(
-			&t.ID, &t.UserID, &t.Type, &t.Payload,
-			&t.Status, &t.Retries, &t.MaxRetries,
-			&t.RunAt, &t.CreatedAt, &t.UpdatedAt,
-		)
+		t, err := scanTask(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tasks from db: %v", err)
 		}
-		tasks = append(tasks, t)
+		tasks = append(tasks, *t)
 	}
 
 	return tasks, nil
 }
 
+func (db *PostgresDB) RetryTask(userID uint64, taskID uuid.UUID) (*task.Task, error) {
+	tx, err := db.Begin(db.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(db.ctx)
+
+	query := `update tasks set status = 'queued', retries = 0, run_at = now(), updated_at = now()
+	where id = @task_id and user_id = @user_id
+	and status = 'failed'
+	returning *`
+	args := pgx.NamedArgs{
+		"task_id": taskID,
+		"user_id": userID,
+	}
+
+	t, err := scanTask(tx.QueryRow(db.ctx, query, args))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			if _, err := db.GetTask(userID, taskID); err != nil {
+				return nil, err
+			}
+			return nil, ErrTaskNotRetryable
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrTaskNotRetryable
+		}
+		return nil, fmt.Errorf("failed to retry task: %v", err)
+	}
+
+	if err := db.unskipDependents(tx, taskID); err != nil {
+		return nil, err
+	}
+
+	if err := db.enqueueTask(tx, taskID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(db.ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return t, nil
+}
+
+func (db *PostgresDB) GetTaskAttempts(userID uint64, taskID uuid.UUID) ([]task.Attempt, error) {
+	if _, err := db.GetTask(userID, taskID); err != nil {
+		return nil, err
+	}
+
+	query := `select id, task_id, attempt, worker_id, started_at, finished_at, duration_ms, error
+	from task_attempts where task_id = @task_id order by attempt, id`
+	args := pgx.NamedArgs{
+		"task_id": taskID,
+	}
+
+	rows, err := db.Query(db.ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select attempts from db: %v", err)
+	}
+	defer rows.Close()
+
+	attempts := []task.Attempt{}
+	for rows.Next() {
+		a := task.Attempt{}
+		err := rows.Scan(
+			&a.ID, &a.TaskID, &a.Attempt, &a.WorkerID,
+			&a.StartedAt, &a.FinishedAt, &a.DurationMs, &a.Error,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attempts from db: %v", err)
+		}
+		attempts = append(attempts, a)
+	}
+
+	return attempts, nil
+}
+
+func (db *PostgresDB) GetTaskLogs(userID uint64, taskID uuid.UUID) ([]task.Log, error) {
+	if _, err := db.GetTask(userID, taskID); err != nil {
+		return nil, err
+	}
+
+	query := `select id, task_id, message, created_at
+	from task_logs where task_id = @task_id order by created_at, id`
+	args := pgx.NamedArgs{
+		"task_id": taskID,
+	}
+
+	rows, err := db.Query(db.ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select logs from db: %v", err)
+	}
+	defer rows.Close()
+
+	logs := []task.Log{}
+	for rows.Next() {
+		l := task.Log{}
+		if err := rows.Scan(&l.ID, &l.TaskID, &l.Message, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan logs from db: %v", err)
+		}
+		logs = append(logs, l)
+	}
+
+	return logs, nil
+}
+
 func (db *PostgresDB) CreateUser(u *user.User) (uint64, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -168,3 +564,18 @@ func (db *PostgresDB) CheckUser(u *user.User) (uint64, error) {
 
 	return id, nil
 }
+
+func scanTask(row pgx.Row) (*task.Task, error) {
+	var t task.Task
+	err := row.Scan(
+		&t.ID, &t.UserID, &t.Type, &t.Payload,
+		&t.Status, &t.Retries, &t.MaxRetries,
+		&t.RunAt, &t.CreatedAt, &t.UpdatedAt, &t.RetryPolicy, &t.Result, &t.Priority,
+		&t.UniqueKey, &t.UniqueUntil, &t.UniqueActive,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/schedule"
+)
+
+func (db *PostgresDB) CreateSchedule(s *schedule.Schedule) (*schedule.Schedule, error) {
+	query := `insert into schedules
+	(id, user_id, cron_expr, timezone, type, payload, max_retries, retry_policy, next_run_at)
+	values (@id, @user_id, @cron_expr, @timezone, @type, @payload, @max_retries, @retry_policy, @next_run_at)
+	returning *`
+	args := pgx.NamedArgs{
+		"id":           s.ID,
+		"user_id":      s.UserID,
+		"cron_expr":    s.CronExpr,
+		"timezone":     s.Timezone,
+		"type":         s.Type,
+		"payload":      s.Payload,
+		"max_retries":  s.MaxRetries,
+		"retry_policy": s.RetryPolicy,
+		"next_run_at":  s.NextRunAt,
+	}
+
+	createdSchedule, err := scanSchedule(db.QueryRow(db.ctx, query, args))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert schedule into db: %v", err)
+	}
+
+	return createdSchedule, nil
+}
+
+func (db *PostgresDB) GetSchedule(userID uint64, scheduleID uuid.UUID) (*schedule.Schedule, error) {
+	query := "select * from schedules where id = @schedule_id and user_id = @user_id"
+	args := pgx.NamedArgs{
+		"schedule_id": scheduleID,
+		"user_id":     userID,
+	}
+
+	s, err := scanSchedule(db.QueryRow(db.ctx, query, args))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to select schedule from db: %v", err)
+	}
+
+	return s, nil
+}
+
+func (db *PostgresDB) GetAllSchedules(userID uint64) ([]schedule.Schedule, error) {
+	query := "select * from schedules where user_id = @user_id order by created_at, id"
+	args := pgx.NamedArgs{
+		"user_id": userID,
+	}
+
+	rows, err := db.Query(db.ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select schedules from db: %v", err)
+	}
+	defer rows.Close()
+
+	schedules := []schedule.Schedule{}
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedules from db: %v", err)
+		}
+		schedules = append(schedules, *s)
+	}
+
+	return schedules, nil
+}
+
+func (db *PostgresDB) DeleteSchedule(userID uint64, scheduleID uuid.UUID) (*schedule.Schedule, error) {
+	query := "delete from schedules where id = @schedule_id and user_id = @user_id returning *"
+	args := pgx.NamedArgs{
+		"schedule_id": scheduleID,
+		"user_id":     userID,
+	}
+
+	s, err := scanSchedule(db.QueryRow(db.ctx, query, args))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to delete schedule from db: %v", err)
+	}
+
+	return s, nil
+}
+
+func scanSchedule(row pgx.Row) (*schedule.Schedule, error) {
+	var s schedule.Schedule
+	err := row.Scan(
+		&s.ID, &s.UserID, &s.CronExpr, &s.Timezone, &s.Type, &s.Payload,
+		&s.MaxRetries, &s.RetryPolicy, &s.NextRunAt, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+func (db *SQLiteDB) CreateTasks(tasks []*task.Task) ([]*task.Task, error) {
+	tx, err := db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	createdTasks := make([]*task.Task, 0, len(tasks))
+	for _, t := range tasks {
+		status := "queued"
+		if t.RunAt != nil {
+			status = "postponed"
+		}
+
+		query, args := insertSQLiteTaskQuery(t, status)
+		createdTask, err := scanSQLiteTask(tx.QueryRowContext(db.ctx, query, args...))
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert task into db: %v", err)
+		}
+
+		if createdTask.Status == "queued" {
+			if err := db.enqueueTask(tx, createdTask.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		createdTasks = append(createdTasks, createdTask)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return createdTasks, nil
+}
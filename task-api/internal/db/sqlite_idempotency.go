@@ -0,0 +1,108 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+func (db *SQLiteDB) CreateTaskIdempotent(t *task.Task, key IdempotencyKey) (*task.Task, int, bool, error) {
+	tx, err := db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := "delete from idempotency_keys where user_id = @user_id and key = @key and created_at < @expires_before"
+	_, err = tx.ExecContext(db.ctx, query,
+		sql.Named("user_id", t.UserID),
+		sql.Named("key", key.Key),
+		sql.Named("expires_before", sqlite.Time(time.Now().Add(-key.Retention))),
+	)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to delete expired idempotency key: %v", err)
+	}
+
+	query = `insert into idempotency_keys (user_id, key, fingerprint) values (@user_id, @key, @fingerprint)
+		on conflict (user_id, key) do nothing`
+	res, err := tx.ExecContext(db.ctx, query,
+		sql.Named("user_id", t.UserID),
+		sql.Named("key", key.Key),
+		sql.Named("fingerprint", key.Fingerprint),
+	)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to insert idempotency key into db: %v", err)
+	}
+
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to insert idempotency key into db: %v", err)
+	}
+
+	if inserted == 0 {
+		replayedTask, statusCode, err := db.replayIdempotencyKey(tx, t.UserID, key)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		return replayedTask, statusCode, true, nil
+	}
+
+	createdTask, err := db.createTask(tx, t)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	statusCode := responseStatusCode(t, createdTask)
+
+	query = `update idempotency_keys set task_id = @task_id, response = @response, status_code = @status_code
+		where user_id = @user_id and key = @key`
+	_, err = tx.ExecContext(db.ctx, query,
+		sql.Named("user_id", t.UserID),
+		sql.Named("key", key.Key),
+		sql.Named("task_id", createdTask.ID),
+		sql.Named("response", sqlite.JSON{V: createdTask}),
+		sql.Named("status_code", statusCode),
+	)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to save response of idempotent request: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, false, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return createdTask, statusCode, false, nil
+}
+
+func (db *SQLiteDB) replayIdempotencyKey(tx *sql.Tx, userID uint64, key IdempotencyKey) (*task.Task, int, error) {
+	query := "select fingerprint, response, status_code from idempotency_keys where user_id = @user_id and key = @key"
+
+	var fingerprint string
+	var response sql.NullString
+	var statusCode int
+	err := tx.QueryRowContext(db.ctx, query,
+		sql.Named("user_id", userID),
+		sql.Named("key", key.Key),
+	).Scan(&fingerprint, &response, &statusCode)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to select idempotency key from db: %v", err)
+	}
+
+	if fingerprint != key.Fingerprint {
+		return nil, 0, ErrIdempotencyKeyMismatch
+	}
+	if !response.Valid {
+		return nil, 0, fmt.Errorf("response of idempotent request is not saved")
+	}
+
+	var t task.Task
+	if err := json.Unmarshal([]byte(response.String), &t); err != nil {
+		return nil, 0, fmt.Errorf("failed to unmarshal response of idempotent request: %v", err)
+	}
+
+	return &t, statusCode, nil
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/schedule"
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+)
+
+func (db *SQLiteDB) CreateSchedule(s *schedule.Schedule) (*schedule.Schedule, error) {
+	query := `insert into schedules
+	(id, user_id, cron_expr, timezone, type, payload, max_retries, retry_policy, next_run_at)
+	values (@id, @user_id, @cron_expr, @timezone, @type, @payload, @max_retries, @retry_policy, @next_run_at)
+	returning *`
+
+	createdSchedule, err := scanSQLiteSchedule(db.QueryRowContext(db.ctx, query,
+		sql.Named("id", s.ID),
+		sql.Named("user_id", s.UserID),
+		sql.Named("cron_expr", s.CronExpr),
+		sql.Named("timezone", s.Timezone),
+		sql.Named("type", s.Type),
+		sql.Named("payload", sqlite.JSON{V: s.Payload}),
+		sql.Named("max_retries", s.MaxRetries),
+		sql.Named("retry_policy", sqlite.JSON{V: s.RetryPolicy}),
+		sql.Named("next_run_at", sqlite.Time(s.NextRunAt)),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert schedule into db: %v", err)
+	}
+
+	return createdSchedule, nil
+}
+
+func (db *SQLiteDB) GetSchedule(userID uint64, scheduleID uuid.UUID) (*schedule.Schedule, error) {
+	query := "select * from schedules where id = @schedule_id and user_id = @user_id"
+
+	s, err := scanSQLiteSchedule(db.QueryRowContext(db.ctx, query,
+		sql.Named("schedule_id", scheduleID),
+		sql.Named("user_id", userID),
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to select schedule from db: %v", err)
+	}
+
+	return s, nil
+}
+
+func (db *SQLiteDB) GetAllSchedules(userID uint64) ([]schedule.Schedule, error) {
+	query := "select * from schedules where user_id = @user_id order by created_at, id"
+
+	rows, err := db.QueryContext(db.ctx, query, sql.Named("user_id", userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to select schedules from db: %v", err)
+	}
+	defer rows.Close()
+
+	schedules := []schedule.Schedule{}
+	for rows.Next() {
+		s, err := scanSQLiteSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedules from db: %v", err)
+		}
+		schedules = append(schedules, *s)
+	}
+
+	return schedules, nil
+}
+
+func (db *SQLiteDB) DeleteSchedule(userID uint64, scheduleID uuid.UUID) (*schedule.Schedule, error) {
+	query := "delete from schedules where id = @schedule_id and user_id = @user_id returning *"
+
+	s, err := scanSQLiteSchedule(db.QueryRowContext(db.ctx, query,
+		sql.Named("schedule_id", scheduleID),
+		sql.Named("user_id", userID),
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to delete schedule from db: %v", err)
+	}
+
+	return s, nil
+}
+
+func scanSQLiteSchedule(row sqliteRow) (*schedule.Schedule, error) {
+	var s schedule.Schedule
+	err := row.Scan(
+		&s.ID, &s.UserID, &s.CronExpr, &s.Timezone, &s.Type, sqlite.JSON{V: &s.Payload},
+		&s.MaxRetries, sqlite.JSON{V: &s.RetryPolicy}, &s.NextRunAt, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
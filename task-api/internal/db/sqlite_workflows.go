@@ -0,0 +1,107 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+func (db *SQLiteDB) CreateWorkflow(w *task.Workflow, order []string) (*task.Workflow, error) {
+	tx, err := db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := "insert into workflows (id, user_id) values (@id, @user_id) returning id, user_id, created_at"
+
+	createdWorkflow := task.Workflow{Tasks: make(map[string]task.Task, len(order))}
+	err = tx.QueryRowContext(db.ctx, query,
+		sql.Named("id", w.ID),
+		sql.Named("user_id", w.UserID),
+	).Scan(&createdWorkflow.ID, &createdWorkflow.UserID, &createdWorkflow.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert workflow into db: %v", err)
+	}
+
+	for _, name := range order {
+		t := w.Tasks[name]
+
+		createdTask, err := db.createTask(tx, &t)
+		if err != nil {
+			return nil, err
+		}
+
+		query = "insert into workflow_tasks (workflow_id, name, task_id) values (@workflow_id, @name, @task_id)"
+		_, err = tx.ExecContext(db.ctx, query,
+			sql.Named("workflow_id", w.ID),
+			sql.Named("name", name),
+			sql.Named("task_id", t.ID),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert task of workflow into db: %v", err)
+		}
+
+		createdWorkflow.Tasks[name] = *createdTask
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &createdWorkflow, nil
+}
+
+func (db *SQLiteDB) GetWorkflow(userID uint64, workflowID uuid.UUID) (*task.Workflow, error) {
+	query := "select id, user_id, created_at from workflows where id = @workflow_id and user_id = @user_id"
+
+	w := task.Workflow{Tasks: map[string]task.Task{}}
+	err := db.QueryRowContext(db.ctx, query,
+		sql.Named("workflow_id", workflowID),
+		sql.Named("user_id", userID),
+	).Scan(&w.ID, &w.UserID, &w.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to select workflow from db: %v", err)
+	}
+
+	query = `select wt.name, t.id, t.user_id, t.type, t.payload, t.status, t.retries,
+	t.max_retries, t.run_at, t.created_at, t.updated_at, t.retry_policy, t.result, t.priority,
+	(select json_group_array(depends_on) from (
+		select depends_on from task_dependencies d where d.task_id = t.id order by depends_on
+	))
+	from workflow_tasks wt join tasks t on t.id = wt.task_id
+	where wt.workflow_id = @workflow_id`
+
+	rows, err := db.QueryContext(db.ctx, query, sql.Named("workflow_id", workflowID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tasks of workflow from db: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var t task.Task
+		err := rows.Scan(
+			&name, &t.ID, &t.UserID, &t.Type, sqlite.JSON{V: &t.Payload},
+			&t.Status, &t.Retries, &t.MaxRetries,
+			&t.RunAt, &t.CreatedAt, &t.UpdatedAt, sqlite.JSON{V: &t.RetryPolicy}, sqlite.JSON{V: &t.Result}, &t.Priority,
+			sqlite.JSON{V: &t.DependsOn},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tasks of workflow from db: %v", err)
+		}
+		if len(t.DependsOn) == 0 {
+			t.DependsOn = nil
+		}
+		w.Tasks[name] = t
+	}
+
+	return &w, nil
+}
@@ -0,0 +1,589 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/user"
+)
+
+type SQLiteDB struct {
+	*sql.DB
+	ctx context.Context
+}
+
+type sqliteQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type sqliteRow interface {
+	Scan(dest ...interface{}) error
+}
+
+func NewSQLiteDB(path string) (*SQLiteDB, error) {
+	ctx := context.Background()
+
+	db, err := sqlite.NewSQLiteDB(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDB{db, ctx}, nil
+}
+
+func (db *SQLiteDB) Ping(ctx context.Context) error {
+	return db.PingContext(ctx)
+}
+
+func (db *SQLiteDB) Close() {
+	db.DB.Close()
+}
+
+func (db *SQLiteDB) CreateTask(t *task.Task) (*task.Task, error) {
+	tx, err := db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	createdTask, err := db.createTask(tx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return createdTask, nil
+}
+
+func (db *SQLiteDB) createTask(tx *sql.Tx, t *task.Task) (*task.Task, error) {
+	status := "queued"
+	if t.RunAt != nil {
+		status = "postponed"
+	}
+
+	if len(t.DependsOn) > 0 {
+		dependenciesStatus, err := db.selectDependencies(tx, t.UserID, t.DependsOn)
+		if err != nil {
+			return nil, err
+		}
+		if dependenciesStatus != "" {
+			status = dependenciesStatus
+		}
+	}
+
+	if t.UniqueKey != nil {
+		if err := db.releaseUniqueKey(tx, t.UserID, *t.UniqueKey); err != nil {
+			return nil, err
+		}
+	}
+
+	query, args := insertSQLiteTaskQuery(t, status)
+	createdTask, err := scanSQLiteTask(tx.QueryRowContext(db.ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows && t.UniqueKey != nil {
+			return db.selectUniqueTask(tx, t.UserID, *t.UniqueKey)
+		}
+		return nil, fmt.Errorf("failed to insert task into db: %v", err)
+	}
+
+	if len(t.DependsOn) > 0 {
+		query = "insert into task_dependencies (task_id, depends_on) select @task_id, value from json_each(@depends_on)"
+		_, err := tx.ExecContext(db.ctx, query,
+			sql.Named("task_id", t.ID),
+			sql.Named("depends_on", sqlite.JSON{V: t.DependsOn}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert dependencies of task into db: %v", err)
+		}
+		createdTask.DependsOn = t.DependsOn
+	}
+
+	if createdTask.Status == "queued" {
+		if err := db.enqueueTask(tx, createdTask.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return createdTask, nil
+}
+
+func insertSQLiteTaskQuery(t *task.Task, status string) (string, []interface{}) {
+	query := "insert into tasks (id, user_id, type, payload, status, max_retries, retry_policy, priority, unique_key, unique_until"
+	values := "values (@id, @user_id, @type, @payload, @status, @max_retries, @retry_policy, @priority, @unique_key, @unique_until"
+
+	args := []interface{}{
+		sql.Named("id", t.ID),
+		sql.Named("user_id", t.UserID),
+		sql.Named("type", t.Type),
+		sql.Named("payload", sqlite.JSON{V: t.Payload}),
+		sql.Named("status", status),
+		sql.Named("max_retries", t.MaxRetries),
+		sql.Named("retry_policy", sqlite.JSON{V: t.RetryPolicy}),
+		sql.Named("priority", t.Priority),
+		sql.Named("unique_key", t.UniqueKey),
+		sql.Named("unique_until", sqlite.NullTime(t.UniqueUntil)),
+	}
+
+	if t.RunAt != nil {
+		args = append(args, sql.Named("run_at", sqlite.Time(*t.RunAt)))
+		query += ", run_at"
+		values += ", @run_at"
+	}
+
+	if t.UniqueKey != nil {
+		query += ", unique_active"
+		values += ", true"
+	}
+
+	query += ") " + values + ")"
+	if t.UniqueKey != nil {
+		query += " on conflict (user_id, unique_key) where unique_active and " + activeStatuses + " do nothing"
+	}
+	query += " returning *"
+
+	return query, args
+}
+
+func (db *SQLiteDB) releaseUniqueKey(tx *sql.Tx, userID uint64, uniqueKey string) error {
+	query := `update tasks set unique_active = null
+	where user_id = @user_id and unique_key = @unique_key and unique_active and unique_until <= @now`
+
+	_, err := tx.ExecContext(db.ctx, query,
+		sql.Named("user_id", userID),
+		sql.Named("unique_key", uniqueKey),
+		sql.Named("now", sqlite.Time(time.Now())),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release expired unique key: %v", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) selectUniqueTask(tx *sql.Tx, userID uint64, uniqueKey string) (*task.Task, error) {
+	query := "select * from tasks where user_id = @user_id and unique_key = @unique_key and unique_active and " + activeStatuses
+
+	t, err := scanSQLiteTask(tx.QueryRowContext(db.ctx, query,
+		sql.Named("user_id", userID),
+		sql.Named("unique_key", uniqueKey),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to select task with same unique key: %v", err)
+	}
+
+	return t, nil
+}
+
+func (db *SQLiteDB) enqueueTask(tx *sql.Tx, taskID uuid.UUID) error {
+	query := "insert into outbox (task_id) values (@task_id)"
+
+	if _, err := tx.ExecContext(db.ctx, query, sql.Named("task_id", taskID)); err != nil {
+		return fmt.Errorf("failed to insert task into outbox: %v", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) selectDependencies(tx *sql.Tx, userID uint64, dependsOn []uuid.UUID) (string, error) {
+	query := "select status from tasks where id in (select value from json_each(@depends_on)) and user_id = @user_id"
+
+	rows, err := tx.QueryContext(db.ctx, query,
+		sql.Named("depends_on", sqlite.JSON{V: dependsOn}),
+		sql.Named("user_id", userID),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to select dependencies of task: %v", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	status := ""
+	for rows.Next() {
+		var parentStatus string
+		if err := rows.Scan(&parentStatus); err != nil {
+			return "", fmt.Errorf("failed to scan dependencies of task: %v", err)
+		}
+		count++
+
+		switch parentStatus {
+		case "done":
+		case "failed", "cancelled", "skipped":
+			status = "skipped"
+		default:
+			if status == "" {
+				status = "waiting"
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to select dependencies of task: %v", err)
+	}
+
+	if count != len(dependsOn) {
+		return "", ErrDependencyNotFound
+	}
+
+	return status, nil
+}
+
+func (db *SQLiteDB) skipDependents(tx *sql.Tx, taskID uuid.UUID) error {
+	query := `with recursive dependents as (
+		select task_id from task_dependencies where depends_on = @task_id
+		union
+		select d.task_id from task_dependencies d join dependents p on d.depends_on = p.task_id
+	)
+	update tasks set status = 'skipped', updated_at = @now
+	where id in (select task_id from dependents) and status = 'waiting'`
+
+	_, err := tx.ExecContext(db.ctx, query,
+		sql.Named("task_id", taskID),
+		sql.Named("now", sqlite.Time(time.Now())),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to skip dependents of task: %v", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) unskipDependents(tx *sql.Tx, taskID uuid.UUID) error {
+	query := `update tasks set status = 'waiting', updated_at = @now
+	where status = 'skipped'
+	and id in (select task_id from task_dependencies where depends_on in (select value from json_each(@task_ids)))
+	and not exists (
+		select 1 from task_dependencies d join tasks p on p.id = d.depends_on
+		where d.task_id = tasks.id and p.status in ('failed', 'cancelled', 'skipped')
+	)
+	returning id`
+
+	taskIDs := []uuid.UUID{taskID}
+	for len(taskIDs) > 0 {
+		rows, err := tx.QueryContext(db.ctx, query,
+			sql.Named("task_ids", sqlite.JSON{V: taskIDs}),
+			sql.Named("now", sqlite.Time(time.Now())),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to unskip dependents of task: %v", err)
+		}
+
+		taskIDs = nil
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan dependents of task: %v", err)
+			}
+			taskIDs = append(taskIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to unskip dependents of task: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) GetTask(userID uint64, taskID uuid.UUID) (*task.Task, error) {
+	return db.getTask(db, userID, taskID)
+}
+
+func (db *SQLiteDB) getTask(q sqliteQuerier, userID uint64, taskID uuid.UUID) (*task.Task, error) {
+	query := "select * from tasks where id = @task_id and user_id = @user_id"
+
+	t, err := scanSQLiteTask(q.QueryRowContext(db.ctx, query,
+		sql.Named("task_id", taskID),
+		sql.Named("user_id", userID),
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to select task from db: %v", err)
+	}
+
+	return t, nil
+}
+
+func (db *SQLiteDB) CancelTask(userID uint64, taskID uuid.UUID) (*task.Task, error) {
+	tx, err := db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := `update tasks set status = 'cancelled', updated_at = @now
+	where id = @task_id and user_id = @user_id
+	and status in ('queued', 'postponed', 'processing', 'waiting')
+	returning *`
+
+	t, err := scanSQLiteTask(tx.QueryRowContext(db.ctx, query,
+		sql.Named("task_id", taskID),
+		sql.Named("user_id", userID),
+		sql.Named("now", sqlite.Time(time.Now())),
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			if _, err := db.getTask(tx, userID, taskID); err != nil {
+				return nil, err
+			}
+			return nil, ErrTaskNotCancellable
+		}
+		return nil, fmt.Errorf("failed to cancel task: %v", err)
+	}
+
+	if err := db.skipDependents(tx, taskID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return t, nil
+}
+
+func (db *SQLiteDB) GetAllTasks(userID uint64, filter TaskFilter) ([]task.Task, error) {
+	query := "select * from tasks where user_id = @user_id"
+	args := []interface{}{
+		sql.Named("user_id", userID),
+		sql.Named("limit", filter.Limit),
+	}
+
+	if len(filter.Statuses) > 0 {
+		query += " and status in (select value from json_each(@statuses))"
+		args = append(args, sql.Named("statuses", sqlite.JSON{V: filter.Statuses}))
+	}
+	if len(filter.Types) > 0 {
+		query += " and type in (select value from json_each(@types))"
+		args = append(args, sql.Named("types", sqlite.JSON{V: filter.Types}))
+	}
+	if filter.CreatedAfter != nil {
+		query += " and created_at >= @created_after"
+		args = append(args, sql.Named("created_after", sqlite.Time(*filter.CreatedAfter)))
+	}
+	if filter.CreatedBefore != nil {
+		query += " and created_at < @created_before"
+		args = append(args, sql.Named("created_before", sqlite.Time(*filter.CreatedBefore)))
+	}
+	if filter.RunAfter != nil {
+		query += " and run_at >= @run_after"
+		args = append(args, sql.Named("run_after", sqlite.Time(*filter.RunAfter)))
+	}
+	if filter.RunBefore != nil {
+		query += " and run_at < @run_before"
+		args = append(args, sql.Named("run_before", sqlite.Time(*filter.RunBefore)))
+	}
+
+	sortColumn := "created_at"
+	if filter.SortBy == "run_at" {
+		sortColumn = "run_at"
+	}
+
+	direction, comparison := "asc", ">"
+	if filter.Desc {
+		direction, comparison = "desc", "<"
+	}
+
+	if filter.After != nil {
+		query += fmt.Sprintf(" and (%s, id) %s (@cursor_value, @cursor_id)", sortColumn, comparison)
+		args = append(args,
+			sql.Named("cursor_value", sqlite.Time(filter.After.Value)),
+			sql.Named("cursor_id", filter.After.ID),
+		)
+	}
+
+	query += fmt.Sprintf(" order by %s %s, id %s limit @limit", sortColumn, direction, direction)
+
+	rows, err := db.QueryContext(db.ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tasks from db: %v", err)
+	}
+	defer rows.Close()
+
+	tasks := []task.Task{}
+	for rows.Next() {
+		t, err := scanSQLiteTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tasks from db: %v", err)
+		}
+		tasks = append(tasks, *t)
+	}
+
+	return tasks, nil
+}
+
+func (db *SQLiteDB) RetryTask(userID uint64, taskID uuid.UUID) (*task.Task, error) {
+	tx, err := db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := `update tasks set status = 'queued', retries = 0, run_at = @now, updated_at = @now
+	where id = @task_id and user_id = @user_id
+	and status = 'failed'
+	returning *`
+
+	t, err := scanSQLiteTask(tx.QueryRowContext(db.ctx, query,
+		sql.Named("task_id", taskID),
+		sql.Named("user_id", userID),
+		sql.Named("now", sqlite.Time(time.Now())),
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			if _, err := db.getTask(tx, userID, taskID); err != nil {
+				return nil, err
+			}
+			return nil, ErrTaskNotRetryable
+		}
+		if sqlite.IsUniqueViolation(err) {
+			return nil, ErrTaskNotRetryable
+		}
+		return nil, fmt.Errorf("failed to retry task: %v", err)
+	}
+
+	if err := db.unskipDependents(tx, taskID); err != nil {
+		return nil, err
+	}
+
+	if err := db.enqueueTask(tx, taskID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return t, nil
+}
+
+func (db *SQLiteDB) GetTaskAttempts(userID uint64, taskID uuid.UUID) ([]task.Attempt, error) {
+	if _, err := db.GetTask(userID, taskID); err != nil {
+		return nil, err
+	}
+
+	query := `select id, task_id, attempt, worker_id, started_at, finished_at, duration_ms, error
+	from task_attempts where task_id = @task_id order by attempt, id`
+
+	rows, err := db.QueryContext(db.ctx, query, sql.Named("task_id", taskID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to select attempts from db: %v", err)
+	}
+	defer rows.Close()
+
+	attempts := []task.Attempt{}
+	for rows.Next() {
+		a := task.Attempt{}
+		err := rows.Scan(
+			&a.ID, &a.TaskID, &a.Attempt, &a.WorkerID,
+			&a.StartedAt, &a.FinishedAt, &a.DurationMs, &a.Error,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attempts from db: %v", err)
+		}
+		attempts = append(attempts, a)
+	}
+
+	return attempts, nil
+}
+
+func (db *SQLiteDB) GetTaskLogs(userID uint64, taskID uuid.UUID) ([]task.Log, error) {
+	if _, err := db.GetTask(userID, taskID); err != nil {
+		return nil, err
+	}
+
+	query := `select id, task_id, message, created_at
+	from task_logs where task_id = @task_id order by created_at, id`
+
+	rows, err := db.QueryContext(db.ctx, query, sql.Named("task_id", taskID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to select logs from db: %v", err)
+	}
+	defer rows.Close()
+
+	logs := []task.Log{}
+	for rows.Next() {
+		l := task.Log{}
+		if err := rows.Scan(&l.ID, &l.TaskID, &l.Message, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan logs from db: %v", err)
+		}
+		logs = append(logs, l)
+	}
+
+	return logs, nil
+}
+
+func (db *SQLiteDB) CreateUser(u *user.User) (uint64, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate hash: %v", err)
+	}
+
+	query := "insert into users (email, password_hash) values (@email, @hash) returning id"
+
+	var userID uint64
+	err = db.QueryRowContext(db.ctx, query,
+		sql.Named("email", u.Email),
+		sql.Named("hash", string(hash)),
+	).Scan(&userID)
+	if err != nil {
+		if sqlite.IsUniqueViolation(err) {
+			return 0, ErrUserAlreadyExist
+		}
+		return 0, fmt.Errorf("failed to insert user into db: %v", err)
+	}
+
+	return userID, nil
+}
+
+func (db *SQLiteDB) CheckUser(u *user.User) (uint64, error) {
+	query := "select id, password_hash from users where email = @email"
+
+	var id uint64
+	var hash []byte
+	err := db.QueryRowContext(db.ctx, query, sql.Named("email", u.Email)).Scan(&id, &hash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrNoRows
+		}
+		return 0, fmt.Errorf("failed to select user from db: %v", err)
+	}
+
+	err = bcrypt.CompareHashAndPassword(hash, []byte(u.Password))
+	if err != nil {
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return 0, ErrIncorrectPassword
+		}
+		return 0, fmt.Errorf("failed to compare hash and password: %v", err)
+	}
+
+	return id, nil
+}
+
+func scanSQLiteTask(row sqliteRow) (*task.Task, error) {
+	var t task.Task
+	err := row.Scan(
+		&t.ID, &t.UserID, &t.Type, sqlite.JSON{V: &t.Payload},
+		&t.Status, &t.Retries, &t.MaxRetries,
+		&t.RunAt, &t.CreatedAt, &t.UpdatedAt, sqlite.JSON{V: &t.RetryPolicy}, sqlite.JSON{V: &t.Result}, &t.Priority,
+		&t.UniqueKey, &t.UniqueUntil, &t.UniqueActive,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,409 @@
+package db
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/schedule"
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/user"
+)
+
+func newTestSQLiteDB(t *testing.T) (*SQLiteDB, uint64) {
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "taskflow.db"))
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	userID, err := db.CreateUser(&user.User{Email: "test@test.com", Password: "password"})
+	require.NoError(t, err)
+
+	return db, userID
+}
+
+func newTestTask(userID uint64) *task.Task {
+	return &task.Task{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Type:       "send_email",
+		Payload:    map[string]interface{}{"to": "test@test.com"},
+		MaxRetries: 3,
+	}
+}
+
+func TestSQLiteUsers(t *testing.T) {
+	db, userID := newTestSQLiteDB(t)
+
+	_, err := db.CreateUser(&user.User{Email: "test@test.com", Password: "password"})
+	assert.Equal(t, ErrUserAlreadyExist, err)
+
+	id, err := db.CheckUser(&user.User{Email: "test@test.com", Password: "password"})
+	assert.NoError(t, err)
+	assert.Equal(t, userID, id)
+
+	_, err = db.CheckUser(&user.User{Email: "test@test.com", Password: "wrong"})
+	assert.Equal(t, ErrIncorrectPassword, err)
+
+	_, err = db.CheckUser(&user.User{Email: "unknown@test.com", Password: "password"})
+	assert.Equal(t, ErrNoRows, err)
+}
+
+func TestSQLiteTasks(t *testing.T) {
+	db, userID := newTestSQLiteDB(t)
+
+	retryPolicy := &task.RetryPolicy{InitialDelayMs: 500, Multiplier: 2, MaxDelayMs: 10000}
+	queued := newTestTask(userID)
+	queued.RetryPolicy = retryPolicy
+
+	created, err := db.CreateTask(queued)
+	require.NoError(t, err)
+	assert.Equal(t, "queued", created.Status)
+	assert.Equal(t, queued.Payload, created.Payload)
+	assert.Equal(t, retryPolicy, created.RetryPolicy)
+	assert.NotNil(t, created.RunAt)
+
+	runAt := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+	postponed := newTestTask(userID)
+	postponed.Type = "download_files"
+	postponed.RunAt = &runAt
+	postponed.Priority = 5
+
+	created, err = db.CreateTask(postponed)
+	require.NoError(t, err)
+	assert.Equal(t, "postponed", created.Status)
+	assert.Equal(t, uint8(5), created.Priority)
+	assert.Equal(t, runAt, *created.RunAt)
+	assert.Nil(t, created.RetryPolicy)
+
+	got, err := db.GetTask(userID, queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queued.ID, got.ID)
+
+	_, err = db.GetTask(userID+1, queued.ID)
+	assert.Equal(t, ErrNoRows, err)
+
+	tasks, err := db.GetAllTasks(userID, TaskFilter{Statuses: []string{"postponed"}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, postponed.ID, tasks[0].ID)
+
+	tasks, err = db.GetAllTasks(userID, TaskFilter{SortBy: "run_at", Desc: true, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, postponed.ID, tasks[0].ID)
+
+	tasks, err = db.GetAllTasks(userID, TaskFilter{
+		SortBy: "run_at",
+		Desc:   true,
+		Limit:  10,
+		After:  &TaskCursor{Value: *tasks[0].RunAt, ID: tasks[0].ID},
+	})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, queued.ID, tasks[0].ID)
+
+	cancelled, err := db.CancelTask(userID, queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", cancelled.Status)
+
+	_, err = db.CancelTask(userID, queued.ID)
+	assert.Equal(t, ErrTaskNotCancellable, err)
+
+	cancelled, err = db.CancelTask(userID, postponed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", cancelled.Status)
+
+	_, err = db.RetryTask(userID, queued.ID)
+	assert.Equal(t, ErrTaskNotRetryable, err)
+
+	_, err = db.RetryTask(userID, uuid.New())
+	assert.Equal(t, ErrNoRows, err)
+
+	logs, err := db.GetTaskLogs(userID, queued.ID)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "task has been created", logs[0].Message)
+	assert.Equal(t, `updated status from "queued" to "cancelled"`, logs[1].Message)
+
+	attempts, err := db.GetTaskAttempts(userID, queued.ID)
+	require.NoError(t, err)
+	assert.Empty(t, attempts)
+}
+
+func TestSQLiteCreateTasks(t *testing.T) {
+	db, userID := newTestSQLiteDB(t)
+
+	runAt := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+	queued := newTestTask(userID)
+	postponed := newTestTask(userID)
+	postponed.RunAt = &runAt
+	postponed.Priority = 2
+
+	created, err := db.CreateTasks([]*task.Task{queued, postponed})
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	assert.Equal(t, queued.ID, created[0].ID)
+	assert.Equal(t, "queued", created[0].Status)
+	assert.Equal(t, postponed.ID, created[1].ID)
+	assert.Equal(t, "postponed", created[1].Status)
+	assert.Equal(t, uint8(2), created[1].Priority)
+
+	var outbox int
+	require.NoError(t, db.QueryRowContext(db.ctx, "select count(*) from outbox").Scan(&outbox))
+	assert.Equal(t, 1, outbox)
+
+	duplicate := newTestTask(userID)
+	duplicate.ID = queued.ID
+	_, err = db.CreateTasks([]*task.Task{newTestTask(userID), duplicate})
+	assert.Error(t, err)
+
+	tasks, err := db.GetAllTasks(userID, TaskFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestSQLiteIdempotencyKeys(t *testing.T) {
+	db, userID := newTestSQLiteDB(t)
+
+	key := IdempotencyKey{Key: "key-1", Fingerprint: "fingerprint", Retention: time.Hour}
+
+	created, statusCode, replayed, err := db.CreateTaskIdempotent(newTestTask(userID), key)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, statusCode)
+	assert.False(t, replayed)
+
+	again, statusCode, replayed, err := db.CreateTaskIdempotent(newTestTask(userID), key)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, statusCode)
+	assert.True(t, replayed)
+	assert.Equal(t, created.ID, again.ID)
+
+	mismatched := key
+	mismatched.Fingerprint = "other"
+	_, _, _, err = db.CreateTaskIdempotent(newTestTask(userID), mismatched)
+	assert.Equal(t, ErrIdempotencyKeyMismatch, err)
+
+	expired := key
+	expired.Retention = 0
+	recreated, _, replayed, err := db.CreateTaskIdempotent(newTestTask(userID), expired)
+	require.NoError(t, err)
+	assert.False(t, replayed)
+	assert.NotEqual(t, created.ID, recreated.ID)
+
+	uniqueKey := "unique-1"
+	unique := newTestTask(userID)
+	unique.UniqueKey = &uniqueKey
+	uniqueTask, err := db.CreateTask(unique)
+	require.NoError(t, err)
+
+	dedupKey := IdempotencyKey{Key: "key-2", Fingerprint: "fingerprint", Retention: time.Hour}
+	for _, wantReplayed := range []bool{false, true} {
+		duplicate := newTestTask(userID)
+		duplicate.UniqueKey = &uniqueKey
+		deduplicated, statusCode, replayed, err := db.CreateTaskIdempotent(duplicate, dedupKey)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, wantReplayed, replayed)
+		assert.Equal(t, uniqueTask.ID, deduplicated.ID)
+	}
+
+	tasks, err := db.GetAllTasks(userID, TaskFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 3)
+}
+
+func TestSQLiteUniqueKeys(t *testing.T) {
+	db, userID := newTestSQLiteDB(t)
+
+	uniqueKey := "https://go.dev/"
+	first := newTestTask(userID)
+	first.UniqueKey = &uniqueKey
+
+	created, err := db.CreateTask(first)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, created.ID)
+	assert.Equal(t, &uniqueKey, created.UniqueKey)
+
+	duplicate := newTestTask(userID)
+	duplicate.UniqueKey = &uniqueKey
+	created, err = db.CreateTask(duplicate)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, created.ID)
+
+	_, err = db.CancelTask(userID, first.ID)
+	require.NoError(t, err)
+
+	created, err = db.CreateTask(duplicate)
+	require.NoError(t, err)
+	assert.Equal(t, duplicate.ID, created.ID)
+
+	expired := time.Now().Add(-time.Second)
+	_, err = db.ExecContext(db.ctx, "update tasks set unique_until = ? where id = ?", sqlite.Time(expired), duplicate.ID)
+	require.NoError(t, err)
+
+	next := newTestTask(userID)
+	next.UniqueKey = &uniqueKey
+	created, err = db.CreateTask(next)
+	require.NoError(t, err)
+	assert.Equal(t, next.ID, created.ID)
+
+	released, err := db.GetTask(userID, duplicate.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &uniqueKey, released.UniqueKey)
+	assert.Nil(t, released.UniqueActive)
+
+	again := newTestTask(userID)
+	again.UniqueKey = &uniqueKey
+	created, err = db.CreateTask(again)
+	require.NoError(t, err)
+	assert.Equal(t, next.ID, created.ID)
+}
+
+func TestSQLiteDependencies(t *testing.T) {
+	db, userID := newTestSQLiteDB(t)
+
+	parent := newTestTask(userID)
+	_, err := db.CreateTask(parent)
+	require.NoError(t, err)
+
+	child := newTestTask(userID)
+	child.DependsOn = []uuid.UUID{parent.ID}
+	created, err := db.CreateTask(child)
+	require.NoError(t, err)
+	assert.Equal(t, "waiting", created.Status)
+	assert.Equal(t, []uuid.UUID{parent.ID}, created.DependsOn)
+
+	orphan := newTestTask(userID)
+	orphan.DependsOn = []uuid.UUID{uuid.New()}
+	_, err = db.CreateTask(orphan)
+	assert.Equal(t, ErrDependencyNotFound, err)
+
+	_, err = db.CancelTask(userID, parent.ID)
+	require.NoError(t, err)
+
+	got, err := db.GetTask(userID, child.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "skipped", got.Status)
+
+	late := newTestTask(userID)
+	late.DependsOn = []uuid.UUID{parent.ID}
+	created, err = db.CreateTask(late)
+	require.NoError(t, err)
+	assert.Equal(t, "skipped", created.Status)
+}
+
+func TestSQLiteRetryTaskUnskipsDependents(t *testing.T) {
+	db, userID := newTestSQLiteDB(t)
+
+	parent := newTestTask(userID)
+	_, err := db.CreateTask(parent)
+	require.NoError(t, err)
+
+	cancelled := newTestTask(userID)
+	_, err = db.CreateTask(cancelled)
+	require.NoError(t, err)
+	_, err = db.CancelTask(userID, cancelled.ID)
+	require.NoError(t, err)
+
+	child := newTestTask(userID)
+	child.DependsOn = []uuid.UUID{parent.ID}
+	_, err = db.CreateTask(child)
+	require.NoError(t, err)
+
+	grandchild := newTestTask(userID)
+	grandchild.DependsOn = []uuid.UUID{child.ID}
+	_, err = db.CreateTask(grandchild)
+	require.NoError(t, err)
+
+	sibling := newTestTask(userID)
+	sibling.DependsOn = []uuid.UUID{parent.ID, cancelled.ID}
+	_, err = db.CreateTask(sibling)
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(db.ctx, "update tasks set status = 'failed' where id = ?", parent.ID)
+	require.NoError(t, err)
+	_, err = db.ExecContext(db.ctx, "update tasks set status = 'skipped' where id in (?, ?)", child.ID, grandchild.ID)
+	require.NoError(t, err)
+
+	retried, err := db.RetryTask(userID, parent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "queued", retried.Status)
+
+	for id, status := range map[uuid.UUID]string{
+		child.ID:      "waiting",
+		grandchild.ID: "waiting",
+		sibling.ID:    "skipped",
+	} {
+		got, err := db.GetTask(userID, id)
+		require.NoError(t, err)
+		assert.Equal(t, status, got.Status)
+	}
+}
+
+func TestSQLiteWorkflows(t *testing.T) {
+	db, userID := newTestSQLiteDB(t)
+
+	download := newTestTask(userID)
+	email := newTestTask(userID)
+	email.DependsOn = []uuid.UUID{download.ID}
+
+	w := &task.Workflow{
+		ID:     uuid.New(),
+		UserID: userID,
+		Tasks: map[string]task.Task{
+			"download": *download,
+			"email":    *email,
+		},
+	}
+
+	created, err := db.CreateWorkflow(w, []string{"download", "email"})
+	require.NoError(t, err)
+	assert.Equal(t, "queued", created.Tasks["download"].Status)
+	assert.Equal(t, "waiting", created.Tasks["email"].Status)
+
+	got, err := db.GetWorkflow(userID, w.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Tasks, 2)
+	assert.Nil(t, got.Tasks["download"].DependsOn)
+	assert.Equal(t, []uuid.UUID{download.ID}, got.Tasks["email"].DependsOn)
+
+	_, err = db.GetWorkflow(userID, uuid.New())
+	assert.Equal(t, ErrNoRows, err)
+}
+
+func TestSQLiteSchedules(t *testing.T) {
+	db, userID := newTestSQLiteDB(t)
+
+	nextRunAt := time.Now().Add(time.Minute).UTC().Truncate(time.Millisecond)
+	s := &schedule.Schedule{
+		ID:         uuid.New(),
+		UserID:     userID,
+		CronExpr:   "*/5 * * * *",
+		Timezone:   "UTC",
+		Type:       "send_email",
+		Payload:    map[string]interface{}{"to": "test@test.com"},
+		MaxRetries: 3,
+		NextRunAt:  nextRunAt,
+	}
+
+	created, err := db.CreateSchedule(s)
+	require.NoError(t, err)
+	assert.Equal(t, nextRunAt, created.NextRunAt)
+
+	schedules, err := db.GetAllSchedules(userID)
+	require.NoError(t, err)
+	assert.Len(t, schedules, 1)
+
+	deleted, err := db.DeleteSchedule(userID, s.ID)
+	require.NoError(t, err)
+	assert.Equal(t, s.ID, deleted.ID)
+
+	_, err = db.GetSchedule(userID, s.ID)
+	assert.Equal(t, ErrNoRows, err)
+}
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const activeStatuses = "status in ('queued', 'postponed', 'processing', 'waiting')"
+
+type TaskFilter struct {
+	Statuses      []string
+	Types         []string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	RunAfter      *time.Time
+	RunBefore     *time.Time
+	SortBy        string
+	Desc          bool
+	Limit         int
+	After         *TaskCursor
+}
+
+type TaskCursor struct {
+	Value time.Time
+	ID    uuid.UUID
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+func (db *PostgresDB) CreateWorkflow(w *task.Workflow, order []string) (*task.Workflow, error) {
+	tx, err := db.Begin(db.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(db.ctx)
+
+	query := "insert into workflows (id, user_id) values (@id, @user_id) returning id, user_id, created_at"
+	args := pgx.NamedArgs{
+		"id":      w.ID,
+		"user_id": w.UserID,
+	}
+
+	createdWorkflow := task.Workflow{Tasks: make(map[string]task.Task, len(order))}
+	err = tx.QueryRow(db.ctx, query, args).Scan(&createdWorkflow.ID, &createdWorkflow.UserID, &createdWorkflow.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert workflow into db: %v", err)
+	}
+
+	for _, name := range order {
+		t := w.Tasks[name]
+
+		createdTask, err := db.createTask(tx, &t)
+		if err != nil {
+			return nil, err
+		}
+
+		query = "insert into workflow_tasks (workflow_id, name, task_id) values (@workflow_id, @name, @task_id)"
+		args = pgx.NamedArgs{
+			"workflow_id": w.ID,
+			"name":        name,
+			"task_id":     t.ID,
+		}
+		if _, err := tx.Exec(db.ctx, query, args); err != nil {
+			return nil, fmt.Errorf("failed to insert task of workflow into db: %v", err)
+		}
+
+		createdWorkflow.Tasks[name] = *createdTask
+	}
+
+	if err := tx.Commit(db.ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &createdWorkflow, nil
+}
+
+func (db *PostgresDB) GetWorkflow(userID uint64, workflowID uuid.UUID) (*task.Workflow, error) {
+	query := "select id, user_id, created_at from workflows where id = @workflow_id and user_id = @user_id"
+	args := pgx.NamedArgs{
+		"workflow_id": workflowID,
+		"user_id":     userID,
+	}
+
+	w := task.Workflow{Tasks: map[string]task.Task{}}
+	if err := db.QueryRow(db.ctx, query, args).Scan(&w.ID, &w.UserID, &w.CreatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to select workflow from db: %v", err)
+	}
+
+	query = `select wt.name, t.id, t.user_id, t.type, t.payload, t.status, t.retries,
+	t.max_retries, t.run_at, t.created_at, t.updated_at, t.retry_policy, t.result, t.priority,
+	array(select depends_on from task_dependencies d where d.task_id = t.id order by depends_on)
+	from workflow_tasks wt join tasks t on t.id = wt.task_id
+	where wt.workflow_id = @workflow_id`
+	args = pgx.NamedArgs{
+		"workflow_id": workflowID,
+	}
+
+	rows, err := db.Query(db.ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tasks of workflow from db: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var t task.Task
+		err := rows.Scan(
+			&name, &t.ID, &t.UserID, &t.Type, &t.Payload,
+			&t.Status, &t.Retries, &t.MaxRetries,
+			&t.RunAt, &t.CreatedAt, &t.UpdatedAt, &t.RetryPolicy, &t.Result, &t.Priority,
+			&t.DependsOn,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tasks of workflow from db: %v", err)
+		}
+		if len(t.DependsOn) == 0 {
+			t.DependsOn = nil
+		}
+		w.Tasks[name] = t
+	}
+
+	return &w, nil
+}
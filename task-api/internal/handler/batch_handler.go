@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) CreateTasksBatchHandler(c *gin.Context) {
+	userID := c.GetUint64(UserIDKey)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBodySize)
+
+	var items []json.RawMessage
+	if err := c.ShouldBindJSON(&items); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.logger.Info("too large body of request", zap.Uint64("user_id", userID), zap.Int64("limit", maxBytesErr.Limit))
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("body of request should not be larger than %d bytes", maxBatchBodySize)})
+			return
+		}
+		h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid body of request"})
+		return
+	}
+
+	if len(items) == 0 || len(items) > maxBatchTasks {
+		h.logger.Info("invalid size of batch", zap.Uint64("user_id", userID), zap.Int("size", len(items)))
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("batch should contain between 1 and %d tasks", maxBatchTasks)})
+		return
+	}
+
+	results := make([]batchTaskResult, len(items))
+	tasks := make([]*task.Task, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	for i, item := range items {
+		results[i].Index = i
+
+		t, err := newBatchTask(userID, item)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		tasks = append(tasks, t)
+		indexes = append(indexes, i)
+	}
+
+	if len(tasks) == 0 {
+		h.logger.Info("no valid tasks in batch", zap.Uint64("user_id", userID), zap.Int("size", len(items)))
+		c.JSON(http.StatusBadRequest, gin.H{"results": results})
+		return
+	}
+
+	createdTasks, err := h.db.CreateTasks(tasks)
+	if err != nil {
+		h.logger.Error("failed to create tasks", zap.Error(err), zap.Uint64("user_id", userID), zap.Int("size", len(tasks)))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tasks"})
+		return
+	}
+
+	for i, t := range createdTasks {
+		results[indexes[i]].Task = t
+		metrics.TasksCreated.WithLabelValues(t.Type).Inc()
+	}
+
+	h.logger.Info("successfully created batch of tasks", zap.Uint64("user_id", userID), zap.Int("created", len(createdTasks)), zap.Int("invalid", len(items)-len(createdTasks)))
+	c.JSON(http.StatusCreated, gin.H{"results": results})
+}
+
+func newBatchTask(userID uint64, item json.RawMessage) (*task.Task, error) {
+	var r createTaskReq
+	if err := json.Unmarshal(item, &r); err != nil {
+		return nil, errors.New("invalid body of task")
+	}
+
+	if err := binding.Validator.ValidateStruct(&r); err != nil {
+		return nil, errors.New("invalid body of task")
+	}
+
+	if len(r.DependsOn) > 0 {
+		return nil, errors.New("depends_on is not supported in batch")
+	}
+
+	if r.UniqueKey != nil || r.UniqueWindowMs != nil {
+		return nil, errors.New("unique_key is not supported in batch")
+	}
+
+	t, err := r.toTask(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := task.ValidateReferences(t.Type, t.Payload, nil); err != nil {
+		return nil, err
+	}
+
+	t.ID, err = uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
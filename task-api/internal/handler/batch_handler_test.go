@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCreateTasksBatchHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	logger := zaptest.NewLogger(t)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	emailPayload := map[string]interface{}{"to": "test@test.com", "subject": "hello"}
+	downloadPayload := map[string]interface{}{"urls": []interface{}{"https://go.dev/"}}
+	priority := uint8(3)
+
+	createdAt := time.Now().UTC()
+	emailTask := task.Task{
+		ID:         uuid.New(),
+		UserID:     1,
+		Type:       "send_email",
+		Payload:    emailPayload,
+		Status:     "queued",
+		MaxRetries: 3,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+	downloadTask := emailTask
+	downloadTask.ID = uuid.New()
+	downloadTask.Type = "download_files"
+	downloadTask.Payload = downloadPayload
+	downloadTask.Priority = priority
+
+	tests := []struct {
+		name            string
+		body            interface{}
+		mockBDSetup     func(db *mocks.MockDB)
+		expectedStatus  int
+		expectedError   string
+		expectedTaskIDs map[int]uuid.UUID
+		expectedErrors  map[int]string
+	}{
+		{
+			name: "Successfully batch creation",
+			body: []interface{}{
+				createTaskReq{Type: "send_email", Payload: emailPayload},
+				createTaskReq{Type: "download_files", Payload: downloadPayload, Priority: &priority},
+			},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateTasks(gomock.Any()).DoAndReturn(func(tasks []*task.Task) ([]*task.Task, error) {
+					if len(tasks) != 2 || tasks[0].Type != "send_email" || tasks[1].Priority != priority {
+						return nil, errors.New("unexpected tasks")
+					}
+					return []*task.Task{&emailTask, &downloadTask}, nil
+				})
+			},
+			expectedStatus:  http.StatusCreated,
+			expectedTaskIDs: map[int]uuid.UUID{0: emailTask.ID, 1: downloadTask.ID},
+			expectedErrors:  map[int]string{},
+		},
+		{
+			name: "Partially invalid batch",
+			body: []interface{}{
+				createTaskReq{Type: "send_sms", Payload: emailPayload},
+				createTaskReq{Type: "send_email", Payload: emailPayload},
+				map[string]interface{}{"type": "send_email"},
+				createTaskReq{Type: "send_email", Payload: map[string]interface{}{"to": "invalid email"}},
+				createTaskReq{Type: "send_email", Payload: emailPayload, DependsOn: []uuid.UUID{uuid.New()}},
+				"not a task",
+				createTaskReq{Type: "process_image", Payload: map[string]interface{}{"path": "{{ download.path }}"}},
+			},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateTasks(gomock.Len(1)).Return([]*task.Task{&emailTask}, nil)
+			},
+			expectedStatus:  http.StatusCreated,
+			expectedTaskIDs: map[int]uuid.UUID{1: emailTask.ID},
+			expectedErrors: map[int]string{
+				0: "invalid type of task",
+				2: "invalid body of task",
+				3: "invalid payload of task",
+				4: "depends_on is not supported in batch",
+				5: "invalid body of task",
+				6: "payload references task \"download\" that is not in depends_on",
+			},
+		},
+		{
+			name: "No valid tasks",
+			body: []interface{}{
+				createTaskReq{Type: "send_sms", Payload: emailPayload},
+			},
+			mockBDSetup:     func(db *mocks.MockDB) {},
+			expectedStatus:  http.StatusBadRequest,
+			expectedTaskIDs: map[int]uuid.UUID{},
+			expectedErrors:  map[int]string{0: "invalid type of task"},
+		},
+		{
+			name:           "Empty batch",
+			body:           []interface{}{},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "batch should contain between 1 and 10000 tasks",
+		},
+		{
+			name:           "Invalid body of request",
+			body:           map[string]interface{}{"type": "send_email"},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid body of request",
+		},
+		{
+			name: "Too large body of request",
+			body: []interface{}{
+				createTaskReq{Type: "send_email", Payload: map[string]interface{}{"to": "test@test.com", "body": strings.Repeat("a", maxBatchBodySize)}},
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  fmt.Sprintf("body of request should not be larger than %d bytes", maxBatchBodySize),
+		},
+		{
+			name: "db error",
+			body: []interface{}{
+				createTaskReq{Type: "send_email", Payload: emailPayload},
+			},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateTasks(gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to create tasks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBDSetup(mockDB)
+
+			bodyBytes, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/tasks/batch", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			h.CreateTasksBatchHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var responseBody struct {
+				Error   string            `json:"error"`
+				Results []batchTaskResult `json:"results"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &responseBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedError, responseBody.Error)
+
+			taskIDs := make(map[int]uuid.UUID)
+			errs := make(map[int]string)
+			for i, r := range responseBody.Results {
+				assert.Equal(t, i, r.Index)
+				if r.Task != nil {
+					taskIDs[r.Index] = r.Task.ID
+				}
+				if r.Error != "" {
+					errs[r.Index] = r.Error
+				}
+			}
+			if tt.expectedError == "" {
+				assert.Equal(t, tt.expectedTaskIDs, taskIDs)
+				assert.Equal(t, tt.expectedErrors, errs)
+			}
+		})
+	}
+}
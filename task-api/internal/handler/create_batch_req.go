@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+const (
+	maxBatchTasks    = 10000
+	maxBatchBodySize = 32 << 20
+)
+
+type batchTaskResult struct {
+	Index int        `json:"index"`
+	Task  *task.Task `json:"task,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+type createScheduleReq struct {
+	CronExpr    string                 `json:"cron_expr" binding:"required"`
+	Timezone    string                 `json:"timezone"`
+	Type        string                 `json:"type" binding:"required"`
+	Payload     map[string]interface{} `json:"payload" binding:"required"`
+	MaxRetries  *uint8                 `json:"max_retries"`
+	RetryPolicy *task.RetryPolicy      `json:"retry_policy"`
+}
@@ -1,10 +1,163 @@
 package handler
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
 
 type createTaskReq struct {
-	Type       string                 `json:"type" binding:"required"`
-	Payload    map[string]interface{} `json:"payload" binding:"required"`
-	MaxRetries *uint8                 `json:"max_retries"`
-	RunAt      *time.Time             `json:"run_at"`
+	Type        string                 `json:"type" binding:"required"`
+	Payload     map[string]interface{} `json:"payload" binding:"required"`
+	MaxRetries  *uint8                 `json:"max_retries"`
+	RunAt       *time.Time             `json:"run_at"`
+	RetryPolicy *task.RetryPolicy      `json:"retry_policy"`
+	DependsOn   []uuid.UUID            `json:"depends_on"`
+	Priority    *uint8                 `json:"priority"`
+
+	UniqueKey      *string `json:"unique_key"`
+	UniqueWindowMs *uint64 `json:"unique_window_ms"`
+}
+
+const maxUniqueKeyLength = 255
+
+func (req *createTaskReq) toTask(userID uint64) (*task.Task, error) {
+	if !task.ValidateType(req.Type) {
+		return nil, errors.New("invalid type of task")
+	}
+
+	if err := task.ValidatePayload(req.Type, req.Payload); err != nil {
+		return nil, errors.New("invalid payload of task")
+	}
+
+	maxRetries, err := normalizeMaxRetries(req.MaxRetries)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RunAt != nil && req.RunAt.Before(time.Now()) {
+		return nil, errors.New("run_at must be in the future")
+	}
+
+	retryPolicy, err := normalizeRetryPolicy(req.RetryPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	priority, err := normalizePriority(req.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	uniqueKey, uniqueUntil, err := normalizeUniqueKey(req.UniqueKey, req.UniqueWindowMs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task.Task{
+		UserID:      userID,
+		Type:        req.Type,
+		Payload:     req.Payload,
+		MaxRetries:  maxRetries,
+		RunAt:       req.RunAt,
+		RetryPolicy: retryPolicy,
+		DependsOn:   normalizeDependsOn(req.DependsOn),
+		Priority:    priority,
+		UniqueKey:   uniqueKey,
+		UniqueUntil: uniqueUntil,
+	}, nil
+}
+
+func (req *createTaskReq) fingerprint() (string, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func normalizeMaxRetries(maxRetries *uint8) (uint8, error) {
+	if maxRetries == nil {
+		return 3, nil
+	}
+
+	if *maxRetries < 1 || *maxRetries > 10 {
+		return 0, fmt.Errorf("max_retries should be between 1 and 10")
+	}
+
+	return *maxRetries, nil
+}
+
+func normalizePriority(priority *uint8) (uint8, error) {
+	if priority == nil {
+		return 0, nil
+	}
+
+	if *priority > task.MaxPriority {
+		return 0, fmt.Errorf("priority should be between 0 and %d", task.MaxPriority)
+	}
+
+	return *priority, nil
+}
+
+func normalizeUniqueKey(uniqueKey *string, uniqueWindowMs *uint64) (*string, *time.Time, error) {
+	if uniqueKey == nil {
+		if uniqueWindowMs != nil {
+			return nil, nil, fmt.Errorf("unique_window_ms requires unique_key")
+		}
+		return nil, nil, nil
+	}
+
+	if *uniqueKey == "" || len(*uniqueKey) > maxUniqueKeyLength {
+		return nil, nil, fmt.Errorf("unique_key should be between 1 and %d characters", maxUniqueKeyLength)
+	}
+
+	if uniqueWindowMs == nil {
+		return uniqueKey, nil, nil
+	}
+
+	if *uniqueWindowMs == 0 {
+		return nil, nil, fmt.Errorf("unique_window_ms should be positive")
+	}
+
+	uniqueUntil := time.Now().Add(time.Duration(*uniqueWindowMs) * time.Millisecond)
+	return uniqueKey, &uniqueUntil, nil
+}
+
+func normalizeRetryPolicy(retryPolicy *task.RetryPolicy) (*task.RetryPolicy, error) {
+	if retryPolicy == nil {
+		return nil, nil
+	}
+
+	p := retryPolicy.WithDefaults()
+	if err := task.ValidateRetryPolicy(p); err != nil {
+		return nil, fmt.Errorf("invalid retry_policy of task: %v", err)
+	}
+
+	return &p, nil
+}
+
+func normalizeDependsOn(dependsOn []uuid.UUID) []uuid.UUID {
+	if len(dependsOn) == 0 {
+		return nil
+	}
+
+	seen := make(map[uuid.UUID]bool, len(dependsOn))
+	unique := make([]uuid.UUID, 0, len(dependsOn))
+	for _, id := range dependsOn {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
 }
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+const maxWorkflowTasks = 100
+
+type createWorkflowReq struct {
+	Tasks []workflowTaskReq `json:"tasks" binding:"required,dive"`
+}
+
+type workflowTaskReq struct {
+	Name        string                 `json:"name" binding:"required"`
+	Type        string                 `json:"type" binding:"required"`
+	Payload     map[string]interface{} `json:"payload" binding:"required"`
+	MaxRetries  *uint8                 `json:"max_retries"`
+	RunAt       *time.Time             `json:"run_at"`
+	RetryPolicy *task.RetryPolicy      `json:"retry_policy"`
+	DependsOn   []string               `json:"depends_on"`
+	Priority    *uint8                 `json:"priority"`
+}
+
+func (req *createWorkflowReq) order() ([]*workflowTaskReq, error) {
+	if len(req.Tasks) == 0 || len(req.Tasks) > maxWorkflowTasks {
+		return nil, fmt.Errorf("workflow should contain between 1 and %d tasks", maxWorkflowTasks)
+	}
+
+	tasks := make(map[string]*workflowTaskReq, len(req.Tasks))
+	for i := range req.Tasks {
+		t := &req.Tasks[i]
+		if _, ok := tasks[t.Name]; ok {
+			return nil, fmt.Errorf("duplicate task name %q", t.Name)
+		}
+		tasks[t.Name] = t
+	}
+
+	inDegree := make(map[string]int, len(req.Tasks))
+	dependents := make(map[string][]string, len(req.Tasks))
+	for _, t := range req.Tasks {
+		seen := make(map[string]bool, len(t.DependsOn))
+		for _, parent := range t.DependsOn {
+			if _, ok := tasks[parent]; !ok {
+				return nil, fmt.Errorf("unknown dependency %q of task %q", parent, t.Name)
+			}
+			if seen[parent] {
+				continue
+			}
+			seen[parent] = true
+			inDegree[t.Name]++
+			dependents[parent] = append(dependents[parent], t.Name)
+		}
+	}
+
+	order := make([]string, 0, len(req.Tasks))
+	for _, t := range req.Tasks {
+		if inDegree[t.Name] == 0 {
+			order = append(order, t.Name)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		for _, child := range dependents[order[i]] {
+			inDegree[child]--
+			if inDegree[child] == 0 {
+				order = append(order, child)
+			}
+		}
+	}
+
+	if len(order) != len(req.Tasks) {
+		return nil, fmt.Errorf("workflow contains a cycle")
+	}
+
+	sorted := make([]*workflowTaskReq, len(order))
+	for i, name := range order {
+		sorted[i] = tasks[name]
+	}
+
+	return sorted, nil
+}
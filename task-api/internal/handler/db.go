@@ -3,14 +3,28 @@ package handler
 import (
 	"github.com/google/uuid"
 
+	"github.com/imightbuyaboat/TaskFlow/pkg/schedule"
 	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/db"
 	"github.com/imightbuyaboat/TaskFlow/task-api/internal/user"
 )
 
 type DB interface {
 	CreateTask(t *task.Task) (*task.Task, error)
+	CreateTaskIdempotent(t *task.Task, key db.IdempotencyKey) (*task.Task, int, bool, error)
+	CreateTasks(tasks []*task.Task) ([]*task.Task, error)
 	GetTask(userID uint64, taskID uuid.UUID) (*task.Task, error)
-	GetAllTasks(userID uint64) ([]task.Task, error)
+	GetAllTasks(userID uint64, filter db.TaskFilter) ([]task.Task, error)
+	CancelTask(userID uint64, taskID uuid.UUID) (*task.Task, error)
+	RetryTask(userID uint64, taskID uuid.UUID) (*task.Task, error)
+	GetTaskAttempts(userID uint64, taskID uuid.UUID) ([]task.Attempt, error)
+	GetTaskLogs(userID uint64, taskID uuid.UUID) ([]task.Log, error)
+	CreateWorkflow(w *task.Workflow, order []string) (*task.Workflow, error)
+	GetWorkflow(userID uint64, workflowID uuid.UUID) (*task.Workflow, error)
+	CreateSchedule(s *schedule.Schedule) (*schedule.Schedule, error)
+	GetSchedule(userID uint64, scheduleID uuid.UUID) (*schedule.Schedule, error)
+	GetAllSchedules(userID uint64) ([]schedule.Schedule, error)
+	DeleteSchedule(userID uint64, scheduleID uuid.UUID) (*schedule.Schedule, error)
 	CreateUser(u *user.User) (uint64, error)
 	CheckUser(u *user.User) (uint64, error)
 }
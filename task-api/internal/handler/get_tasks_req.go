@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/db"
+)
+
+const (
+	defaultTasksLimit = 50
+	maxTasksLimit     = 500
+)
+
+type getTasksReq struct {
+	Status        []string   `form:"status"`
+	Type          []string   `form:"type"`
+	CreatedAfter  *time.Time `form:"created_after"`
+	CreatedBefore *time.Time `form:"created_before"`
+	RunAfter      *time.Time `form:"run_after"`
+	RunBefore     *time.Time `form:"run_before"`
+	SortBy        string     `form:"sort_by"`
+	Order         string     `form:"order"`
+	Limit         *int       `form:"limit"`
+	Cursor        string     `form:"cursor"`
+}
+
+type tasksCursor struct {
+	SortBy string    `json:"sort_by"`
+	Order  string    `json:"order"`
+	Value  time.Time `json:"value"`
+	ID     uuid.UUID `json:"id"`
+}
+
+func (req *getTasksReq) toFilter() (db.TaskFilter, error) {
+	filter := db.TaskFilter{
+		Statuses:      req.Status,
+		Types:         req.Type,
+		CreatedAfter:  toUTC(req.CreatedAfter),
+		CreatedBefore: toUTC(req.CreatedBefore),
+		RunAfter:      toUTC(req.RunAfter),
+		RunBefore:     toUTC(req.RunBefore),
+		Limit:         defaultTasksLimit,
+	}
+
+	for _, t := range req.Type {
+		if !task.ValidateType(t) {
+			return filter, fmt.Errorf("invalid type of task")
+		}
+	}
+
+	if req.SortBy == "" {
+		req.SortBy = "created_at"
+	}
+	if req.SortBy != "created_at" && req.SortBy != "run_at" {
+		return filter, fmt.Errorf("sort_by should be created_at or run_at")
+	}
+	filter.SortBy = req.SortBy
+
+	if req.Order == "" {
+		req.Order = "desc"
+	}
+	if req.Order != "asc" && req.Order != "desc" {
+		return filter, fmt.Errorf("order should be asc or desc")
+	}
+	filter.Desc = req.Order == "desc"
+
+	if req.Limit != nil {
+		if *req.Limit < 1 || *req.Limit > maxTasksLimit {
+			return filter, fmt.Errorf("limit should be between 1 and %d", maxTasksLimit)
+		}
+		filter.Limit = *req.Limit
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeTasksCursor(req.Cursor)
+		if err != nil {
+			return filter, fmt.Errorf("invalid cursor")
+		}
+		if cursor.SortBy != req.SortBy || cursor.Order != req.Order {
+			return filter, fmt.Errorf("cursor does not match sort_by and order")
+		}
+		filter.After = &db.TaskCursor{Value: cursor.Value, ID: cursor.ID}
+	}
+
+	return filter, nil
+}
+
+func (req *getTasksReq) nextCursor(last *task.Task) string {
+	cursor := tasksCursor{
+		SortBy: req.SortBy,
+		Order:  req.Order,
+		Value:  last.CreatedAt,
+		ID:     last.ID,
+	}
+	if req.SortBy == "run_at" && last.RunAt != nil {
+		cursor.Value = *last.RunAt
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTasksCursor(raw string) (*tasksCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor tasksCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+func toUTC(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
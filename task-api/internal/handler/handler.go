@@ -2,11 +2,14 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
 	"github.com/imightbuyaboat/TaskFlow/pkg/task"
 	"github.com/imightbuyaboat/TaskFlow/task-api/internal/auth"
 	"github.com/imightbuyaboat/TaskFlow/task-api/internal/db"
@@ -14,19 +17,23 @@ import (
 	"go.uber.org/zap"
 )
 
-const UserIDKey = "userID"
+const (
+	UserIDKey = "userID"
+
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+	idempotencyKeyRetention = 24 * time.Hour
+)
 
 type Handler struct {
 	db           DB
-	queue        Queue
 	tokenManager auth.TokenManager
 	logger       *zap.Logger
 }
 
-func NewHandler(db DB, queue Queue, tm auth.TokenManager, logger *zap.Logger) (*Handler, error) {
+func NewHandler(db DB, tm auth.TokenManager, logger *zap.Logger) (*Handler, error) {
 	return &Handler{
 		db:           db,
-		queue:        queue,
 		tokenManager: tm,
 		logger:       logger,
 	}, nil
@@ -90,6 +97,13 @@ func (h *Handler) LoginHandler(c *gin.Context) {
 func (h *Handler) CreateTaskHandler(c *gin.Context) {
 	userID := c.GetUint64(UserIDKey)
 
+	idempotencyKey := c.GetHeader(idempotencyKeyHeader)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		h.logger.Info("too long idempotency key", zap.Uint64("user_id", userID))
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Idempotency-Key should not be longer than %d characters", maxIdempotencyKeyLength)})
+		return
+	}
+
 	var req createTaskReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID))
@@ -97,31 +111,35 @@ func (h *Handler) CreateTaskHandler(c *gin.Context) {
 		return
 	}
 
-	if !task.ValidateType(req.Type) {
-		h.logger.Info("invalid body of request", zap.Uint64("user_id", userID), zap.String("type", req.Type))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type of task"})
+	t, err := req.toTask(userID)
+	if err != nil {
+		h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	err := task.ValidatePayload(req.Type, req.Payload)
+	parentTypes, err := h.typesOfParents(userID, t.Payload, t.DependsOn)
 	if err != nil {
-		h.logger.Info("invalid body of request", zap.Uint64("user_id", userID), zap.Any("payload", req.Payload))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload of task"})
+		if errors.Is(err, db.ErrNoRows) {
+			h.logger.Info("incorrect task_id in depends_on", zap.Uint64("user_id", userID), zap.Any("depends_on", req.DependsOn))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect task ID in depends_on"})
+			return
+		}
+		h.logger.Error("failed to get parents of task", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
 		return
 	}
 
-	if req.MaxRetries == nil {
-		defaultMaxRetries := uint8(3)
-		req.MaxRetries = &defaultMaxRetries
-	} else if *req.MaxRetries < 1 || *req.MaxRetries > 10 {
-		h.logger.Info("invalid body of request", zap.Uint64("user_id", userID))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "max_retries should be between 1 and 10"})
+	if err := task.ValidateReferences(t.Type, t.Payload, parentTypes); err != nil {
+		h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID), zap.Any("payload", req.Payload))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if req.RunAt != nil && req.RunAt.Before(time.Now()) {
-		h.logger.Info("invalid body of request", zap.Uint64("user_id", userID))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "run_at must be in the future"})
+	fingerprint, err := req.fingerprint()
+	if err != nil {
+		h.logger.Error("failed to compute fingerprint of request", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
@@ -132,30 +150,51 @@ func (h *Handler) CreateTaskHandler(c *gin.Context) {
 		return
 	}
 
-	t := task.Task{
-		ID:         taskID,
-		UserID:     userID,
-		Type:       req.Type,
-		Payload:    req.Payload,
-		MaxRetries: *req.MaxRetries,
-		RunAt:      req.RunAt,
+	t.ID = taskID
+
+	var createdTask *task.Task
+	var statusCode int
+	var replayed bool
+	if idempotencyKey == "" {
+		createdTask, err = h.db.CreateTask(t)
+	} else {
+		createdTask, statusCode, replayed, err = h.db.CreateTaskIdempotent(t, db.IdempotencyKey{
+			Key:         idempotencyKey,
+			Fingerprint: fingerprint,
+			Retention:   idempotencyKeyRetention,
+		})
 	}
-	createdTask, err := h.db.CreateTask(&t)
 	if err != nil {
+		if errors.Is(err, db.ErrDependencyNotFound) {
+			h.logger.Info("incorrect task_id in depends_on", zap.Uint64("user_id", userID), zap.Any("depends_on", req.DependsOn))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect task ID in depends_on"})
+			return
+		}
+		if errors.Is(err, db.ErrIdempotencyKeyMismatch) {
+			h.logger.Info("idempotency key was used with different request", zap.Uint64("user_id", userID), zap.String("idempotency_key", idempotencyKey))
+			c.JSON(http.StatusConflict, gin.H{"error": "Idempotency-Key was already used with a different request"})
+			return
+		}
 		h.logger.Error("failed to create task", zap.Error(err), zap.Uint64("user_id", userID), zap.String("task_id", taskID.String()))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
 		return
 	}
 
-	if req.RunAt == nil {
-		err = h.queue.Publish(createdTask)
-		if err != nil {
-			h.logger.Error("failed to publish task in queue", zap.Error(err), zap.Uint64("user_id", userID), zap.String("task_id", taskID.String()))
-		} else {
-			h.logger.Info("successfully publish task", zap.Uint64("user_id", userID), zap.String("task_id", taskID.String()))
-		}
+	if replayed {
+		h.logger.Info("replayed idempotent request", zap.Uint64("user_id", userID), zap.String("task_id", createdTask.ID.String()), zap.String("idempotency_key", idempotencyKey))
+		c.Header("Idempotent-Replayed", "true")
+		c.JSON(statusCode, createdTask)
+		return
 	}
 
+	if t.UniqueKey != nil && createdTask.ID != taskID {
+		h.logger.Info("found pending task with same unique_key", zap.Uint64("user_id", userID), zap.String("task_id", createdTask.ID.String()))
+		c.JSON(http.StatusOK, createdTask)
+		return
+	}
+
+	metrics.TasksCreated.WithLabelValues(createdTask.Type).Inc()
+
 	h.logger.Info("successfully created task", zap.Uint64("user_id", userID), zap.String("task_id", taskID.String()))
 	c.JSON(http.StatusCreated, createdTask)
 }
@@ -164,10 +203,8 @@ func (h *Handler) GetTaskHandler(c *gin.Context) {
 	userID := c.GetUint64(UserIDKey)
 
 	rawTaskID := c.Param("id")
-	taskID, err := uuid.Parse(rawTaskID)
-	if err != nil {
-		h.logger.Error("failed to parse task_id", zap.Error(err), zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse task ID"})
+	taskID, ok := h.parseTaskID(c, userID, rawTaskID)
+	if !ok {
 		return
 	}
 
@@ -187,16 +224,182 @@ func (h *Handler) GetTaskHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, t)
 }
 
+func (h *Handler) CancelTaskHandler(c *gin.Context) {
+	userID := c.GetUint64(UserIDKey)
+
+	rawTaskID := c.Param("id")
+	taskID, ok := h.parseTaskID(c, userID, rawTaskID)
+	if !ok {
+		return
+	}
+
+	t, err := h.db.CancelTask(userID, taskID)
+	if err != nil {
+		if errors.Is(err, db.ErrNoRows) {
+			h.logger.Info("incorrect task_id", zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect task ID"})
+			return
+		}
+		if errors.Is(err, db.ErrTaskNotCancellable) {
+			h.logger.Info("task cannot be cancelled", zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+			c.JSON(http.StatusConflict, gin.H{"error": "Task cannot be cancelled"})
+			return
+		}
+		h.logger.Error("failed to cancel task", zap.Error(err), zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel task"})
+		return
+	}
+
+	h.logger.Info("successfully cancel task", zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+	c.JSON(http.StatusOK, t)
+}
+
+func (h *Handler) RetryTaskHandler(c *gin.Context) {
+	userID := c.GetUint64(UserIDKey)
+
+	rawTaskID := c.Param("id")
+	taskID, ok := h.parseTaskID(c, userID, rawTaskID)
+	if !ok {
+		return
+	}
+
+	t, err := h.db.RetryTask(userID, taskID)
+	if err != nil {
+		if errors.Is(err, db.ErrNoRows) {
+			h.logger.Info("incorrect task_id", zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect task ID"})
+			return
+		}
+		if errors.Is(err, db.ErrTaskNotRetryable) {
+			h.logger.Info("task cannot be retried", zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+			c.JSON(http.StatusConflict, gin.H{"error": "Task cannot be retried"})
+			return
+		}
+		h.logger.Error("failed to retry task", zap.Error(err), zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry task"})
+		return
+	}
+
+	h.logger.Info("successfully retry task", zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+	c.JSON(http.StatusOK, t)
+}
+
+func (h *Handler) GetTaskAttemptsHandler(c *gin.Context) {
+	userID := c.GetUint64(UserIDKey)
+
+	rawTaskID := c.Param("id")
+	taskID, ok := h.parseTaskID(c, userID, rawTaskID)
+	if !ok {
+		return
+	}
+
+	attempts, err := h.db.GetTaskAttempts(userID, taskID)
+	if err != nil {
+		if errors.Is(err, db.ErrNoRows) {
+			h.logger.Info("incorrect task_id", zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect task ID"})
+			return
+		}
+		h.logger.Error("failed to get attempts of task", zap.Error(err), zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get attempts of task"})
+		return
+	}
+
+	h.logger.Info("successfully get attempts of task", zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+	c.JSON(http.StatusOK, attempts)
+}
+
+func (h *Handler) GetTaskLogsHandler(c *gin.Context) {
+	userID := c.GetUint64(UserIDKey)
+
+	rawTaskID := c.Param("id")
+	taskID, ok := h.parseTaskID(c, userID, rawTaskID)
+	if !ok {
+		return
+	}
+
+	logs, err := h.db.GetTaskLogs(userID, taskID)
+	if err != nil {
+		if errors.Is(err, db.ErrNoRows) {
+			h.logger.Info("incorrect task_id", zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect task ID"})
+			return
+		}
+		h.logger.Error("failed to get logs of task", zap.Error(err), zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get logs of task"})
+		return
+	}
+
+	h.logger.Info("successfully get logs of task", zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+	c.JSON(http.StatusOK, logs)
+}
+
 func (h *Handler) GetAllTasksHandler(c *gin.Context) {
 	userID := c.GetUint64(UserIDKey)
 
-	tasks, err := h.db.GetAllTasks(userID)
+	var req getTasksReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Info("invalid query of request", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query of request"})
+		return
+	}
+
+	filter, err := req.toFilter()
+	if err != nil {
+		h.logger.Info("invalid query of request", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+
+	tasks, err := h.db.GetAllTasks(userID, filter)
 	if err != nil {
 		h.logger.Error("failed to get tasks", zap.Error(err), zap.Uint64("user_id", userID))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tasks"})
 		return
 	}
 
-	h.logger.Info("successfully get tasks", zap.Uint64("user_id", userID))
-	c.JSON(http.StatusOK, tasks)
+	var nextCursor *string
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+		cursor := req.nextCursor(&tasks[limit-1])
+		nextCursor = &cursor
+	}
+
+	h.logger.Info("successfully get tasks", zap.Uint64("user_id", userID), zap.Int("count", len(tasks)))
+	c.JSON(http.StatusOK, gin.H{"tasks": tasks, "next_cursor": nextCursor})
+}
+
+func (h *Handler) parseTaskID(c *gin.Context, userID uint64, rawTaskID string) (uuid.UUID, bool) {
+	taskID, err := uuid.Parse(rawTaskID)
+	if err != nil {
+		h.logger.Error("failed to parse task_id", zap.Error(err), zap.Uint64("user_id", userID), zap.String("task_id", rawTaskID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse task ID"})
+		return uuid.Nil, false
+	}
+
+	return taskID, true
+}
+
+func (h *Handler) typesOfParents(userID uint64, payload map[string]interface{}, dependsOn []uuid.UUID) (map[string]string, error) {
+	types := make(map[string]string)
+	for _, ref := range task.References(payload) {
+		parentID, err := uuid.Parse(ref.Task)
+		if err != nil || !slices.Contains(dependsOn, parentID) {
+			continue
+		}
+		if _, ok := types[ref.Task]; ok {
+			continue
+		}
+
+		parent, err := h.db.GetTask(userID, parentID)
+		if err != nil {
+			return nil, err
+		}
+		types[ref.Task] = parent.Type
+	}
+
+	return types, nil
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,7 +28,7 @@ func TestRegisterHandler(t *testing.T) {
 
 	mockDB := mocks.NewMockDB(ctrl)
 	logger := zaptest.NewLogger(t)
-	h, _ := NewHandler(mockDB, nil, nil, logger)
+	h, _ := NewHandler(mockDB, nil, logger)
 
 	tests := []struct {
 		name           string
@@ -130,7 +131,7 @@ func TestLoginHandler(t *testing.T) {
 	mockDB := mocks.NewMockDB(ctrl)
 	mockTokenManager := mocks.NewMockTokenManager(ctrl)
 	logger := zaptest.NewLogger(t)
-	h, _ := NewHandler(mockDB, nil, mockTokenManager, logger)
+	h, _ := NewHandler(mockDB, mockTokenManager, logger)
 
 	tests := []struct {
 		name                  string
@@ -240,9 +241,8 @@ func TestCreateTaskHandler(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockDB := mocks.NewMockDB(ctrl)
-	mockQueue := mocks.NewMockQueue(ctrl)
 	logger := zaptest.NewLogger(t)
-	h, _ := NewHandler(mockDB, mockQueue, nil, logger)
+	h, _ := NewHandler(mockDB, nil, logger)
 
 	runAt := time.Now().Add(time.Hour)
 	createdAt := time.Now()
@@ -265,12 +265,25 @@ func TestCreateTaskHandler(t *testing.T) {
 	}
 
 	invalidRunAt := time.Now().Add(-1 * time.Hour)
+	parentID := uuid.New()
+
+	priority := uint8(7)
+	invalidPriority := uint8(task.MaxPriority + 1)
+
+	prioritizedTask := createdTask
+	prioritizedTask.Priority = priority
+
+	uniqueKey := "https://go.dev/"
+	uniqueWindowMs := uint64(60000)
+	zeroWindowMs := uint64(0)
+	existingTask := createdTask
+	existingTask.ID = uuid.New()
+	existingTask.UniqueKey = &uniqueKey
 
 	tests := []struct {
 		name           string
 		body           interface{}
 		mockBDSetup    func(db *mocks.MockDB)
-		mockQueueSetup func(q *mocks.MockQueue)
 		expectedStatus int
 		expectedBody   gin.H
 	}{
@@ -287,19 +300,20 @@ func TestCreateTaskHandler(t *testing.T) {
 			mockBDSetup: func(db *mocks.MockDB) {
 				db.EXPECT().CreateTask(gomock.Any()).Return(&createdTask, nil)
 			},
-			mockQueueSetup: func(q *mocks.MockQueue) {},
 			expectedStatus: http.StatusCreated,
 			expectedBody: gin.H{
-				"id":          createdTask.ID.String(),
-				"user_id":     float64(createdTask.UserID),
-				"type":        createdTask.Type,
-				"payload":     createdTask.Payload,
-				"status":      createdTask.Status,
-				"retries":     float64(createdTask.Retries),
-				"max_retries": float64(createdTask.MaxRetries),
-				"run_at":      createdTask.RunAt.Format(time.RFC3339Nano),
-				"created_at":  createdTask.CreatedAt.Format(time.RFC3339Nano),
-				"updated_at":  createdTask.UpdatedAt.Format(time.RFC3339Nano),
+				"id":           createdTask.ID.String(),
+				"user_id":      float64(createdTask.UserID),
+				"type":         createdTask.Type,
+				"payload":      createdTask.Payload,
+				"status":       createdTask.Status,
+				"retries":      float64(createdTask.Retries),
+				"max_retries":  float64(createdTask.MaxRetries),
+				"run_at":       createdTask.RunAt.Format(time.RFC3339Nano),
+				"created_at":   createdTask.CreatedAt.Format(time.RFC3339Nano),
+				"updated_at":   createdTask.UpdatedAt.Format(time.RFC3339Nano),
+				"retry_policy": nil,
+				"priority":     float64(0),
 			},
 		},
 		{
@@ -308,7 +322,6 @@ func TestCreateTaskHandler(t *testing.T) {
 				Type: "send_email",
 			},
 			mockBDSetup:    func(db *mocks.MockDB) {},
-			mockQueueSetup: func(q *mocks.MockQueue) {},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   gin.H{"error": "Invalid body of request"},
 		},
@@ -322,7 +335,6 @@ func TestCreateTaskHandler(t *testing.T) {
 				},
 			},
 			mockBDSetup:    func(db *mocks.MockDB) {},
-			mockQueueSetup: func(q *mocks.MockQueue) {},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   gin.H{"error": "invalid type of task"},
 		},
@@ -335,7 +347,6 @@ func TestCreateTaskHandler(t *testing.T) {
 				},
 			},
 			mockBDSetup:    func(db *mocks.MockDB) {},
-			mockQueueSetup: func(q *mocks.MockQueue) {},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   gin.H{"error": "invalid payload of task"},
 		},
@@ -350,7 +361,6 @@ func TestCreateTaskHandler(t *testing.T) {
 				MaxRetries: new(uint8),
 			},
 			mockBDSetup:    func(db *mocks.MockDB) {},
-			mockQueueSetup: func(q *mocks.MockQueue) {},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   gin.H{"error": "max_retries should be between 1 and 10"},
 		},
@@ -365,10 +375,221 @@ func TestCreateTaskHandler(t *testing.T) {
 				RunAt: &invalidRunAt,
 			},
 			mockBDSetup:    func(db *mocks.MockDB) {},
-			mockQueueSetup: func(q *mocks.MockQueue) {},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   gin.H{"error": "run_at must be in the future"},
 		},
+		{
+			name: "Invalid retry_policy of task",
+			body: createTaskReq{
+				Type: "send_email",
+				Payload: map[string]interface{}{
+					"to":      "test@test.com",
+					"subject": "test",
+				},
+				RetryPolicy: &task.RetryPolicy{
+					InitialDelayMs: 1000,
+					Multiplier:     2,
+					MaxDelayMs:     1000,
+					Jitter:         1.5,
+				},
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "invalid retry_policy of task: jitter must be in the range [0, 1]"},
+		},
+		{
+			name: "Reference to output of parent task",
+			body: createTaskReq{
+				Type: "send_email",
+				Payload: map[string]interface{}{
+					"to":             "test@test.com",
+					"attached_files": []interface{}{"{{ " + parentID.String() + ".path }}"},
+				},
+				DependsOn: []uuid.UUID{parentID},
+			},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetTask(gomock.Any(), parentID).Return(&task.Task{ID: parentID, Type: "process_image"}, nil)
+				db.EXPECT().CreateTask(gomock.Any()).DoAndReturn(func(created *task.Task) (*task.Task, error) {
+					assert.Equal(t, []uuid.UUID{parentID}, created.DependsOn)
+					return nil, errors.New("db error")
+				})
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to create task"},
+		},
+		{
+			name: "Reference of wrong type",
+			body: createTaskReq{
+				Type:      "process_image",
+				Payload:   map[string]interface{}{"path": "{{ " + parentID.String() + ".paths }}"},
+				DependsOn: []uuid.UUID{parentID},
+			},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetTask(gomock.Any(), parentID).Return(&task.Task{ID: parentID, Type: "download_files"}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "reference in field \"path\" of payload has wrong type"},
+		},
+		{
+			name: "Reference to task outside of depends_on",
+			body: createTaskReq{
+				Type: "send_email",
+				Payload: map[string]interface{}{
+					"to":             "test@test.com",
+					"attached_files": []interface{}{"{{ " + parentID.String() + ".path }}"},
+				},
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "payload references task \"" + parentID.String() + "\" that is not in depends_on"},
+		},
+		{
+			name: "Reference to unknown task",
+			body: createTaskReq{
+				Type: "send_email",
+				Payload: map[string]interface{}{
+					"to":             "test@test.com",
+					"attached_files": []interface{}{"{{ " + parentID.String() + ".path }}"},
+				},
+				DependsOn: []uuid.UUID{parentID},
+			},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetTask(gomock.Any(), parentID).Return(nil, pdb.ErrNoRows)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "Incorrect task ID in depends_on"},
+		},
+		{
+			name: "Successfully prioritized task creation",
+			body: createTaskReq{
+				Type: "send_email",
+				Payload: map[string]interface{}{
+					"to":      "test@test.com",
+					"subject": "test",
+				},
+				RunAt:    &runAt,
+				Priority: &priority,
+			},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateTask(gomock.Any()).DoAndReturn(func(t *task.Task) (*task.Task, error) {
+					if t.Priority != priority {
+						return nil, errors.New("unexpected priority")
+					}
+					return &prioritizedTask, nil
+				})
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody: gin.H{
+				"id":           prioritizedTask.ID.String(),
+				"user_id":      float64(prioritizedTask.UserID),
+				"type":         prioritizedTask.Type,
+				"payload":      prioritizedTask.Payload,
+				"status":       prioritizedTask.Status,
+				"retries":      float64(prioritizedTask.Retries),
+				"max_retries":  float64(prioritizedTask.MaxRetries),
+				"run_at":       prioritizedTask.RunAt.Format(time.RFC3339Nano),
+				"created_at":   prioritizedTask.CreatedAt.Format(time.RFC3339Nano),
+				"updated_at":   prioritizedTask.UpdatedAt.Format(time.RFC3339Nano),
+				"retry_policy": nil,
+				"priority":     float64(priority),
+			},
+		},
+		{
+			name: "Existing task with same unique_key",
+			body: createTaskReq{
+				Type: "send_email",
+				Payload: map[string]interface{}{
+					"to":      "test@test.com",
+					"subject": "test",
+				},
+				RunAt:          &runAt,
+				UniqueKey:      &uniqueKey,
+				UniqueWindowMs: &uniqueWindowMs,
+			},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateTask(gomock.Any()).DoAndReturn(func(t *task.Task) (*task.Task, error) {
+					if t.UniqueKey == nil || *t.UniqueKey != uniqueKey || t.UniqueUntil == nil {
+						return nil, errors.New("unexpected unique key")
+					}
+					return &existingTask, nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: gin.H{
+				"id":           existingTask.ID.String(),
+				"user_id":      float64(existingTask.UserID),
+				"type":         existingTask.Type,
+				"payload":      existingTask.Payload,
+				"status":       existingTask.Status,
+				"retries":      float64(existingTask.Retries),
+				"max_retries":  float64(existingTask.MaxRetries),
+				"run_at":       existingTask.RunAt.Format(time.RFC3339Nano),
+				"created_at":   existingTask.CreatedAt.Format(time.RFC3339Nano),
+				"updated_at":   existingTask.UpdatedAt.Format(time.RFC3339Nano),
+				"retry_policy": nil,
+				"priority":     float64(0),
+				"unique_key":   uniqueKey,
+			},
+		},
+		{
+			name: "Unique window without unique_key",
+			body: createTaskReq{
+				Type: "send_email",
+				Payload: map[string]interface{}{
+					"to":      "test@test.com",
+					"subject": "test",
+				},
+				UniqueWindowMs: &uniqueWindowMs,
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "unique_window_ms requires unique_key"},
+		},
+		{
+			name: "Non-positive unique window",
+			body: createTaskReq{
+				Type: "send_email",
+				Payload: map[string]interface{}{
+					"to":      "test@test.com",
+					"subject": "test",
+				},
+				UniqueKey:      &uniqueKey,
+				UniqueWindowMs: &zeroWindowMs,
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "unique_window_ms should be positive"},
+		},
+		{
+			name: "Invalid priority of task",
+			body: createTaskReq{
+				Type: "send_email",
+				Payload: map[string]interface{}{
+					"to":      "test@test.com",
+					"subject": "test",
+				},
+				Priority: &invalidPriority,
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "priority should be between 0 and 9"},
+		},
+		{
+			name: "Incorrect task_id in depends_on",
+			body: createTaskReq{
+				Type: "send_email",
+				Payload: map[string]interface{}{
+					"to":      "test@test.com",
+					"subject": "test",
+				},
+				DependsOn: []uuid.UUID{uuid.New()},
+			},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateTask(gomock.Any()).Return(nil, pdb.ErrDependencyNotFound)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "Incorrect task ID in depends_on"},
+		},
 		{
 			name: "db error",
 			body: createTaskReq{
@@ -381,7 +602,6 @@ func TestCreateTaskHandler(t *testing.T) {
 			mockBDSetup: func(db *mocks.MockDB) {
 				db.EXPECT().CreateTask(gomock.Any()).Return(nil, errors.New("db error"))
 			},
-			mockQueueSetup: func(q *mocks.MockQueue) {},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   gin.H{"error": "Failed to create task"},
 		},
@@ -390,7 +610,6 @@ func TestCreateTaskHandler(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockBDSetup(mockDB)
-			tt.mockQueueSetup(mockQueue)
 
 			bodyBytes, _ := json.Marshal(tt.body)
 			req, _ := http.NewRequest(http.MethodPost, "/api/tasks", bytes.NewBuffer(bodyBytes))
@@ -412,6 +631,119 @@ func TestCreateTaskHandler(t *testing.T) {
 	}
 }
 
+func TestCreateTaskHandlerIdempotencyKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	logger := zaptest.NewLogger(t)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	body := createTaskReq{
+		Type: "send_email",
+		Payload: map[string]interface{}{
+			"to":      "test@test.com",
+			"subject": "test",
+		},
+	}
+	fingerprint, err := body.fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createdTask := task.Task{
+		ID:         uuid.New(),
+		UserID:     1,
+		Type:       body.Type,
+		Payload:    body.Payload,
+		Status:     "queued",
+		MaxRetries: 3,
+	}
+	expectedKey := pdb.IdempotencyKey{Key: "key-1", Fingerprint: fingerprint, Retention: idempotencyKeyRetention}
+
+	tests := []struct {
+		name             string
+		idempotencyKey   string
+		mockBDSetup      func(db *mocks.MockDB)
+		expectedStatus   int
+		expectedReplayed string
+		expectedError    string
+	}{
+		{
+			name:           "First request with key",
+			idempotencyKey: "key-1",
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateTaskIdempotent(gomock.Any(), expectedKey).Return(&createdTask, http.StatusCreated, false, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Repeated request with key",
+			idempotencyKey: "key-1",
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateTaskIdempotent(gomock.Any(), expectedKey).Return(&createdTask, http.StatusCreated, true, nil)
+			},
+			expectedStatus:   http.StatusCreated,
+			expectedReplayed: "true",
+		},
+		{
+			name:           "Repeated request that found task with same unique_key",
+			idempotencyKey: "key-1",
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateTaskIdempotent(gomock.Any(), expectedKey).Return(&createdTask, http.StatusOK, true, nil)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedReplayed: "true",
+		},
+		{
+			name:           "Key used with different request",
+			idempotencyKey: "key-1",
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateTaskIdempotent(gomock.Any(), expectedKey).Return(nil, 0, false, pdb.ErrIdempotencyKeyMismatch)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "Idempotency-Key was already used with a different request",
+		},
+		{
+			name:           "Too long key",
+			idempotencyKey: strings.Repeat("k", maxIdempotencyKeyLength+1),
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Idempotency-Key should not be longer than 255 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBDSetup(mockDB)
+
+			bodyBytes, _ := json.Marshal(body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/tasks", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Idempotency-Key", tt.idempotencyKey)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			h.CreateTaskHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedReplayed, w.Header().Get("Idempotent-Replayed"))
+
+			var responseBody gin.H
+			if err := json.Unmarshal(w.Body.Bytes(), &responseBody); err != nil {
+				t.Fatal(err)
+			}
+			if tt.expectedError != "" {
+				assert.Equal(t, gin.H{"error": tt.expectedError}, responseBody)
+			} else {
+				assert.Equal(t, createdTask.ID.String(), responseBody["id"])
+			}
+		})
+	}
+}
+
 func TestGetTaskHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ctrl := gomock.NewController(t)
@@ -419,7 +751,7 @@ func TestGetTaskHandler(t *testing.T) {
 
 	mockDB := mocks.NewMockDB(ctrl)
 	logger := zaptest.NewLogger(t)
-	h, _ := NewHandler(mockDB, nil, nil, logger)
+	h, _ := NewHandler(mockDB, nil, logger)
 
 	taskID := uuid.New()
 	runAt := time.Now().Add(time.Hour)
@@ -457,16 +789,18 @@ func TestGetTaskHandler(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: gin.H{
-				"id":          gettedTask.ID.String(),
-				"user_id":     float64(gettedTask.UserID),
-				"type":        gettedTask.Type,
-				"payload":     gettedTask.Payload,
-				"status":      gettedTask.Status,
-				"retries":     float64(gettedTask.Retries),
-				"max_retries": float64(gettedTask.MaxRetries),
-				"run_at":      gettedTask.RunAt.Format(time.RFC3339Nano),
-				"created_at":  gettedTask.CreatedAt.Format(time.RFC3339Nano),
-				"updated_at":  gettedTask.UpdatedAt.Format(time.RFC3339Nano),
+				"id":           gettedTask.ID.String(),
+				"user_id":      float64(gettedTask.UserID),
+				"type":         gettedTask.Type,
+				"payload":      gettedTask.Payload,
+				"status":       gettedTask.Status,
+				"retries":      float64(gettedTask.Retries),
+				"max_retries":  float64(gettedTask.MaxRetries),
+				"run_at":       gettedTask.RunAt.Format(time.RFC3339Nano),
+				"created_at":   gettedTask.CreatedAt.Format(time.RFC3339Nano),
+				"updated_at":   gettedTask.UpdatedAt.Format(time.RFC3339Nano),
+				"retry_policy": nil,
+				"priority":     float64(0),
 			},
 		},
 		{
@@ -530,26 +864,139 @@ func TestGetAllTasksHandler(t *testing.T) {
 
 	mockDB := mocks.NewMockDB(ctrl)
 	logger := zaptest.NewLogger(t)
-	h, _ := NewHandler(mockDB, nil, nil, logger)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	createdAt := time.Now().UTC()
+	firstTask := task.Task{
+		ID:         uuid.New(),
+		UserID:     1,
+		Type:       "download_files",
+		Payload:    map[string]interface{}{"urls": []interface{}{"https://go.dev/"}},
+		Status:     "done",
+		MaxRetries: 3,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+	secondTask := firstTask
+	secondTask.ID = uuid.New()
+	secondTask.CreatedAt = createdAt.Add(-time.Minute)
+
+	nextCursor := (&getTasksReq{SortBy: "created_at", Order: "desc"}).nextCursor(&firstTask)
 
 	tests := []struct {
 		name           string
+		query          string
 		mockBDSetup    func(db *mocks.MockDB)
 		expectedStatus int
 		expectedBody   gin.H
 	}{
 		{
-			name: "Successfully get tasks",
+			name:  "Successfully get tasks",
+			query: "",
 			mockBDSetup: func(db *mocks.MockDB) {
-				db.EXPECT().GetAllTasks(gomock.Any()).Return(nil, nil)
+				db.EXPECT().GetAllTasks(gomock.Any(), pdb.TaskFilter{
+					SortBy: "created_at",
+					Desc:   true,
+					Limit:  defaultTasksLimit + 1,
+				}).Return([]task.Task{}, nil)
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   nil,
+			expectedBody:   gin.H{"tasks": []interface{}{}, "next_cursor": nil},
 		},
 		{
-			name: "db error",
+			name:  "Successfully get first page",
+			query: "?limit=1&status=done&type=download_files",
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetAllTasks(gomock.Any(), pdb.TaskFilter{
+					Statuses: []string{"done"},
+					Types:    []string{"download_files"},
+					SortBy:   "created_at",
+					Desc:     true,
+					Limit:    2,
+				}).Return([]task.Task{firstTask, secondTask}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: gin.H{
+				"tasks": []interface{}{
+					map[string]interface{}{
+						"id":           firstTask.ID.String(),
+						"user_id":      float64(firstTask.UserID),
+						"type":         firstTask.Type,
+						"payload":      firstTask.Payload,
+						"status":       firstTask.Status,
+						"retries":      float64(firstTask.Retries),
+						"max_retries":  float64(firstTask.MaxRetries),
+						"run_at":       nil,
+						"created_at":   firstTask.CreatedAt.Format(time.RFC3339Nano),
+						"updated_at":   firstTask.UpdatedAt.Format(time.RFC3339Nano),
+						"retry_policy": nil,
+						"priority":     float64(0),
+					},
+				},
+				"next_cursor": nextCursor,
+			},
+		},
+		{
+			name:  "Successfully get next page",
+			query: "?limit=1&cursor=" + nextCursor,
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetAllTasks(gomock.Any(), pdb.TaskFilter{
+					SortBy: "created_at",
+					Desc:   true,
+					Limit:  2,
+					After:  &pdb.TaskCursor{Value: firstTask.CreatedAt, ID: firstTask.ID},
+				}).Return([]task.Task{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   gin.H{"tasks": []interface{}{}, "next_cursor": nil},
+		},
+		{
+			name:           "Invalid limit",
+			query:          "?limit=0",
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "limit should be between 1 and 500"},
+		},
+		{
+			name:           "Invalid sort_by",
+			query:          "?sort_by=type",
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "sort_by should be created_at or run_at"},
+		},
+		{
+			name:           "Invalid type",
+			query:          "?type=invalid",
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "invalid type of task"},
+		},
+		{
+			name:           "Invalid cursor",
+			query:          "?cursor=invalid",
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "invalid cursor"},
+		},
+		{
+			name:           "Cursor does not match order",
+			query:          "?order=asc&cursor=" + nextCursor,
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "cursor does not match sort_by and order"},
+		},
+		{
+			name:           "Invalid query of request",
+			query:          "?created_after=yesterday",
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "Invalid query of request"},
+		},
+		{
+			name:  "db error",
+			query: "",
 			mockBDSetup: func(db *mocks.MockDB) {
-				db.EXPECT().GetAllTasks(gomock.Any()).Return(nil, errors.New("db error"))
+				db.EXPECT().GetAllTasks(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   gin.H{"error": "Failed to get tasks"},
@@ -560,7 +1007,7 @@ func TestGetAllTasksHandler(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockBDSetup(mockDB)
 
-			req, _ := http.NewRequest(http.MethodGet, "/api/tasks", nil)
+			req, _ := http.NewRequest(http.MethodGet, "/api/tasks"+tt.query, nil)
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -578,3 +1025,455 @@ func TestGetAllTasksHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestCancelTaskHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	logger := zaptest.NewLogger(t)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	taskID := uuid.New()
+	runAt := time.Now().Add(time.Hour)
+	createdAt := time.Now()
+	updatedAt := createdAt
+
+	cancelledTask := task.Task{
+		ID:     taskID,
+		UserID: 1,
+		Type:   "send_email",
+		Payload: map[string]interface{}{
+			"to":      "test@test.com",
+			"subject": "test",
+		},
+		Status:     "cancelled",
+		Retries:    0,
+		MaxRetries: 3,
+		RunAt:      &runAt,
+		CreatedAt:  createdAt,
+		UpdatedAt:  updatedAt,
+	}
+
+	tests := []struct {
+		name           string
+		taskIDStr      string
+		mockBDSetup    func(db *mocks.MockDB)
+		expectedStatus int
+		expectedBody   gin.H
+	}{
+		{
+			name:      "Successfully cancel task",
+			taskIDStr: taskID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CancelTask(gomock.Any(), taskID).Return(&cancelledTask, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: gin.H{
+				"id":           cancelledTask.ID.String(),
+				"user_id":      float64(cancelledTask.UserID),
+				"type":         cancelledTask.Type,
+				"payload":      cancelledTask.Payload,
+				"status":       cancelledTask.Status,
+				"retries":      float64(cancelledTask.Retries),
+				"max_retries":  float64(cancelledTask.MaxRetries),
+				"run_at":       cancelledTask.RunAt.Format(time.RFC3339Nano),
+				"created_at":   cancelledTask.CreatedAt.Format(time.RFC3339Nano),
+				"updated_at":   cancelledTask.UpdatedAt.Format(time.RFC3339Nano),
+				"retry_policy": nil,
+				"priority":     float64(0),
+			},
+		},
+		{
+			name:           "Failed to parse task_id",
+			taskIDStr:      "invalid id",
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to parse task ID"},
+		},
+		{
+			name:      "Incorrect task_id",
+			taskIDStr: uuid.New().String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CancelTask(gomock.Any(), gomock.Any()).Return(nil, pdb.ErrNoRows)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "Incorrect task ID"},
+		},
+		{
+			name:      "Task cannot be cancelled",
+			taskIDStr: taskID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CancelTask(gomock.Any(), taskID).Return(nil, pdb.ErrTaskNotCancellable)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   gin.H{"error": "Task cannot be cancelled"},
+		},
+		{
+			name:      "db error",
+			taskIDStr: taskID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CancelTask(gomock.Any(), taskID).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to cancel task"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBDSetup(mockDB)
+
+			req, _ := http.NewRequest(http.MethodDelete, "/api/tasks/"+tt.taskIDStr, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = gin.Params{gin.Param{
+				Key:   "id",
+				Value: tt.taskIDStr,
+			}}
+
+			h.CancelTaskHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var responseBody gin.H
+			if err := json.Unmarshal(w.Body.Bytes(), &responseBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedBody, responseBody)
+		})
+	}
+}
+
+func TestGetTaskAttemptsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	logger := zaptest.NewLogger(t)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	taskID := uuid.New()
+	startedAt := time.Now().Add(-time.Minute)
+	finishedAt := startedAt.Add(1500 * time.Millisecond)
+	durationMs := int64(1500)
+	errorMessage := "failed to send mail: connection refused"
+
+	attempts := []task.Attempt{
+		{
+			ID:         1,
+			TaskID:     taskID,
+			Attempt:    1,
+			WorkerID:   "worker-host-1",
+			StartedAt:  startedAt,
+			FinishedAt: &finishedAt,
+			DurationMs: &durationMs,
+			Error:      &errorMessage,
+		},
+	}
+
+	tests := []struct {
+		name           string
+		taskIDStr      string
+		mockBDSetup    func(db *mocks.MockDB)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:      "Successfully get attempts",
+			taskIDStr: taskID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetTaskAttempts(gomock.Any(), taskID).Return(attempts, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []interface{}{
+				map[string]interface{}{
+					"id":          float64(1),
+					"task_id":     taskID.String(),
+					"attempt":     float64(1),
+					"worker_id":   "worker-host-1",
+					"started_at":  startedAt.Format(time.RFC3339Nano),
+					"finished_at": finishedAt.Format(time.RFC3339Nano),
+					"duration_ms": float64(durationMs),
+					"error":       errorMessage,
+				},
+			},
+		},
+		{
+			name:           "Failed to parse task_id",
+			taskIDStr:      "invalid id",
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   map[string]interface{}{"error": "Failed to parse task ID"},
+		},
+		{
+			name:      "Incorrect task_id",
+			taskIDStr: uuid.New().String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetTaskAttempts(gomock.Any(), gomock.Any()).Return(nil, pdb.ErrNoRows)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Incorrect task ID"},
+		},
+		{
+			name:      "db error",
+			taskIDStr: taskID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetTaskAttempts(gomock.Any(), taskID).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   map[string]interface{}{"error": "Failed to get attempts of task"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBDSetup(mockDB)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/tasks/"+tt.taskIDStr+"/attempts", nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = gin.Params{gin.Param{
+				Key:   "id",
+				Value: tt.taskIDStr,
+			}}
+
+			h.GetTaskAttemptsHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var responseBody interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &responseBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedBody, responseBody)
+		})
+	}
+}
+
+func TestGetTaskLogsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	logger := zaptest.NewLogger(t)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	taskID := uuid.New()
+	createdAt := time.Now().Add(-time.Minute)
+	updatedAt := createdAt.Add(time.Second)
+
+	logs := []task.Log{
+		{ID: 1, TaskID: taskID, Message: "task has been created", CreatedAt: createdAt},
+		{ID: 2, TaskID: taskID, Message: `updated status from "queued" to "processing"`, CreatedAt: updatedAt},
+	}
+
+	tests := []struct {
+		name           string
+		taskIDStr      string
+		mockBDSetup    func(db *mocks.MockDB)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:      "Successfully get logs",
+			taskIDStr: taskID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetTaskLogs(gomock.Any(), taskID).Return(logs, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []interface{}{
+				map[string]interface{}{
+					"id":         float64(1),
+					"task_id":    taskID.String(),
+					"message":    "task has been created",
+					"created_at": createdAt.Format(time.RFC3339Nano),
+				},
+				map[string]interface{}{
+					"id":         float64(2),
+					"task_id":    taskID.String(),
+					"message":    `updated status from "queued" to "processing"`,
+					"created_at": updatedAt.Format(time.RFC3339Nano),
+				},
+			},
+		},
+		{
+			name:           "Failed to parse task_id",
+			taskIDStr:      "invalid id",
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   map[string]interface{}{"error": "Failed to parse task ID"},
+		},
+		{
+			name:      "Incorrect task_id",
+			taskIDStr: uuid.New().String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetTaskLogs(gomock.Any(), gomock.Any()).Return(nil, pdb.ErrNoRows)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"error": "Incorrect task ID"},
+		},
+		{
+			name:      "db error",
+			taskIDStr: taskID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetTaskLogs(gomock.Any(), taskID).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   map[string]interface{}{"error": "Failed to get logs of task"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBDSetup(mockDB)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/tasks/"+tt.taskIDStr+"/logs", nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = gin.Params{gin.Param{
+				Key:   "id",
+				Value: tt.taskIDStr,
+			}}
+
+			h.GetTaskLogsHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var responseBody interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &responseBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedBody, responseBody)
+		})
+	}
+}
+
+func TestRetryTaskHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	logger := zaptest.NewLogger(t)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	taskID := uuid.New()
+	runAt := time.Now()
+	createdAt := time.Now().Add(-time.Hour)
+	updatedAt := runAt
+
+	retriedTask := task.Task{
+		ID:     taskID,
+		UserID: 1,
+		Type:   "send_email",
+		Payload: map[string]interface{}{
+			"to":      "test@test.com",
+			"subject": "test",
+		},
+		Status:     "queued",
+		Retries:    0,
+		MaxRetries: 3,
+		RunAt:      &runAt,
+		CreatedAt:  createdAt,
+		UpdatedAt:  updatedAt,
+	}
+
+	retriedTaskBody := gin.H{
+		"id":           retriedTask.ID.String(),
+		"user_id":      float64(retriedTask.UserID),
+		"type":         retriedTask.Type,
+		"payload":      retriedTask.Payload,
+		"status":       retriedTask.Status,
+		"retries":      float64(retriedTask.Retries),
+		"max_retries":  float64(retriedTask.MaxRetries),
+		"run_at":       retriedTask.RunAt.Format(time.RFC3339Nano),
+		"created_at":   retriedTask.CreatedAt.Format(time.RFC3339Nano),
+		"updated_at":   retriedTask.UpdatedAt.Format(time.RFC3339Nano),
+		"retry_policy": nil,
+		"priority":     float64(0),
+	}
+
+	tests := []struct {
+		name           string
+		taskIDStr      string
+		mockBDSetup    func(db *mocks.MockDB)
+		expectedStatus int
+		expectedBody   gin.H
+	}{
+		{
+			name:      "Successfully retry task",
+			taskIDStr: taskID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().RetryTask(gomock.Any(), taskID).Return(&retriedTask, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   retriedTaskBody,
+		},
+		{
+			name:           "Failed to parse task_id",
+			taskIDStr:      "invalid id",
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to parse task ID"},
+		},
+		{
+			name:      "Incorrect task_id",
+			taskIDStr: uuid.New().String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().RetryTask(gomock.Any(), gomock.Any()).Return(nil, pdb.ErrNoRows)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "Incorrect task ID"},
+		},
+		{
+			name:      "Task cannot be retried",
+			taskIDStr: taskID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().RetryTask(gomock.Any(), taskID).Return(nil, pdb.ErrTaskNotRetryable)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   gin.H{"error": "Task cannot be retried"},
+		},
+		{
+			name:      "db error",
+			taskIDStr: taskID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().RetryTask(gomock.Any(), taskID).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to retry task"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBDSetup(mockDB)
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/tasks/"+tt.taskIDStr+"/retry", nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = gin.Params{gin.Param{
+				Key:   "id",
+				Value: tt.taskIDStr,
+			}}
+
+			h.RetryTaskHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var responseBody gin.H
+			if err := json.Unmarshal(w.Body.Bytes(), &responseBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedBody, responseBody)
+		})
+	}
+}
@@ -3,9 +3,12 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
 	"github.com/imightbuyaboat/TaskFlow/task-api/internal/auth"
 	"go.uber.org/zap"
 )
@@ -38,3 +41,18 @@ func (h *Handler) AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func (h *Handler) MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
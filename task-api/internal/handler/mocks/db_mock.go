@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: internal/db/db.go
+// Source: internal/handler/db.go
 //
 // Generated by this command:
 //
-//	mockgen -source=internal/db/db.go -destination=internal/handler/mocks/db_mock.go -package=mocks
+//	mockgen -source=internal/handler/db.go -destination=internal/handler/mocks/db_mock.go -package=mocks
 //
 
 // Package mocks is a generated GoMock package.
@@ -13,7 +13,9 @@ import (
 	reflect "reflect"
 
 	uuid "github.com/google/uuid"
+	schedule "github.com/imightbuyaboat/TaskFlow/pkg/schedule"
 	task "github.com/imightbuyaboat/TaskFlow/pkg/task"
+	db "github.com/imightbuyaboat/TaskFlow/task-api/internal/db"
 	user "github.com/imightbuyaboat/TaskFlow/task-api/internal/user"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -42,6 +44,21 @@ func (m *MockDB) EXPECT() *MockDBMockRecorder {
 	return m.recorder
 }
 
+// CancelTask mocks base method.
+func (m *MockDB) CancelTask(userID uint64, taskID uuid.UUID) (*task.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelTask", userID, taskID)
+	ret0, _ := ret[0].(*task.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelTask indicates an expected call of CancelTask.
+func (mr *MockDBMockRecorder) CancelTask(userID, taskID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelTask", reflect.TypeOf((*MockDB)(nil).CancelTask), userID, taskID)
+}
+
 // CheckUser mocks base method.
 func (m *MockDB) CheckUser(u *user.User) (uint64, error) {
 	m.ctrl.T.Helper()
@@ -57,6 +74,21 @@ func (mr *MockDBMockRecorder) CheckUser(u any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUser", reflect.TypeOf((*MockDB)(nil).CheckUser), u)
 }
 
+// CreateSchedule mocks base method.
+func (m *MockDB) CreateSchedule(s *schedule.Schedule) (*schedule.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", s)
+	ret0, _ := ret[0].(*schedule.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule.
+func (mr *MockDBMockRecorder) CreateSchedule(s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*MockDB)(nil).CreateSchedule), s)
+}
+
 // CreateTask mocks base method.
 func (m *MockDB) CreateTask(t *task.Task) (*task.Task, error) {
 	m.ctrl.T.Helper()
@@ -72,6 +104,38 @@ func (mr *MockDBMockRecorder) CreateTask(t any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTask", reflect.TypeOf((*MockDB)(nil).CreateTask), t)
 }
 
+// CreateTaskIdempotent mocks base method.
+func (m *MockDB) CreateTaskIdempotent(t *task.Task, key db.IdempotencyKey) (*task.Task, int, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTaskIdempotent", t, key)
+	ret0, _ := ret[0].(*task.Task)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// CreateTaskIdempotent indicates an expected call of CreateTaskIdempotent.
+func (mr *MockDBMockRecorder) CreateTaskIdempotent(t, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTaskIdempotent", reflect.TypeOf((*MockDB)(nil).CreateTaskIdempotent), t, key)
+}
+
+// CreateTasks mocks base method.
+func (m *MockDB) CreateTasks(tasks []*task.Task) ([]*task.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTasks", tasks)
+	ret0, _ := ret[0].([]*task.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTasks indicates an expected call of CreateTasks.
+func (mr *MockDBMockRecorder) CreateTasks(tasks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTasks", reflect.TypeOf((*MockDB)(nil).CreateTasks), tasks)
+}
+
 // CreateUser mocks base method.
 func (m *MockDB) CreateUser(u *user.User) (uint64, error) {
 	m.ctrl.T.Helper()
@@ -87,19 +151,79 @@ func (mr *MockDBMockRecorder) CreateUser(u any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockDB)(nil).CreateUser), u)
 }
 
+// CreateWorkflow mocks base method.
+func (m *MockDB) CreateWorkflow(w *task.Workflow, order []string) (*task.Workflow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkflow", w, order)
+	ret0, _ := ret[0].(*task.Workflow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWorkflow indicates an expected call of CreateWorkflow.
+func (mr *MockDBMockRecorder) CreateWorkflow(w, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkflow", reflect.TypeOf((*MockDB)(nil).CreateWorkflow), w, order)
+}
+
+// DeleteSchedule mocks base method.
+func (m *MockDB) DeleteSchedule(userID uint64, scheduleID uuid.UUID) (*schedule.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSchedule", userID, scheduleID)
+	ret0, _ := ret[0].(*schedule.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSchedule indicates an expected call of DeleteSchedule.
+func (mr *MockDBMockRecorder) DeleteSchedule(userID, scheduleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSchedule", reflect.TypeOf((*MockDB)(nil).DeleteSchedule), userID, scheduleID)
+}
+
+// GetAllSchedules mocks base method.
+func (m *MockDB) GetAllSchedules(userID uint64) ([]schedule.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllSchedules", userID)
+	ret0, _ := ret[0].([]schedule.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllSchedules indicates an expected call of GetAllSchedules.
+func (mr *MockDBMockRecorder) GetAllSchedules(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllSchedules", reflect.TypeOf((*MockDB)(nil).GetAllSchedules), userID)
+}
+
 // GetAllTasks mocks base method.
-func (m *MockDB) GetAllTasks(userID uint64) ([]task.Task, error) {
+func (m *MockDB) GetAllTasks(userID uint64, filter db.TaskFilter) ([]task.Task, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllTasks", userID)
+	ret := m.ctrl.Call(m, "GetAllTasks", userID, filter)
 	ret0, _ := ret[0].([]task.Task)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllTasks indicates an expected call of GetAllTasks.
-func (mr *MockDBMockRecorder) GetAllTasks(userID any) *gomock.Call {
+func (mr *MockDBMockRecorder) GetAllTasks(userID, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTasks", reflect.TypeOf((*MockDB)(nil).GetAllTasks), userID, filter)
+}
+
+// GetSchedule mocks base method.
+func (m *MockDB) GetSchedule(userID uint64, scheduleID uuid.UUID) (*schedule.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedule", userID, scheduleID)
+	ret0, _ := ret[0].(*schedule.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedule indicates an expected call of GetSchedule.
+func (mr *MockDBMockRecorder) GetSchedule(userID, scheduleID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTasks", reflect.TypeOf((*MockDB)(nil).GetAllTasks), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedule", reflect.TypeOf((*MockDB)(nil).GetSchedule), userID, scheduleID)
 }
 
 // GetTask mocks base method.
@@ -116,3 +240,63 @@ func (mr *MockDBMockRecorder) GetTask(userID, taskID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTask", reflect.TypeOf((*MockDB)(nil).GetTask), userID, taskID)
 }
+
+// GetTaskAttempts mocks base method.
+func (m *MockDB) GetTaskAttempts(userID uint64, taskID uuid.UUID) ([]task.Attempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskAttempts", userID, taskID)
+	ret0, _ := ret[0].([]task.Attempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskAttempts indicates an expected call of GetTaskAttempts.
+func (mr *MockDBMockRecorder) GetTaskAttempts(userID, taskID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskAttempts", reflect.TypeOf((*MockDB)(nil).GetTaskAttempts), userID, taskID)
+}
+
+// GetTaskLogs mocks base method.
+func (m *MockDB) GetTaskLogs(userID uint64, taskID uuid.UUID) ([]task.Log, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskLogs", userID, taskID)
+	ret0, _ := ret[0].([]task.Log)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskLogs indicates an expected call of GetTaskLogs.
+func (mr *MockDBMockRecorder) GetTaskLogs(userID, taskID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskLogs", reflect.TypeOf((*MockDB)(nil).GetTaskLogs), userID, taskID)
+}
+
+// GetWorkflow mocks base method.
+func (m *MockDB) GetWorkflow(userID uint64, workflowID uuid.UUID) (*task.Workflow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkflow", userID, workflowID)
+	ret0, _ := ret[0].(*task.Workflow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkflow indicates an expected call of GetWorkflow.
+func (mr *MockDBMockRecorder) GetWorkflow(userID, workflowID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkflow", reflect.TypeOf((*MockDB)(nil).GetWorkflow), userID, workflowID)
+}
+
+// RetryTask mocks base method.
+func (m *MockDB) RetryTask(userID uint64, taskID uuid.UUID) (*task.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryTask", userID, taskID)
+	ret0, _ := ret[0].(*task.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryTask indicates an expected call of RetryTask.
+func (mr *MockDBMockRecorder) RetryTask(userID, taskID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryTask", reflect.TypeOf((*MockDB)(nil).RetryTask), userID, taskID)
+}
@@ -1,9 +0,0 @@
-package handler
-
-import (
-	"github.com/imightbuyaboat/TaskFlow/pkg/task"
-)
-
-type Queue interface {
-	Publish(t *task.Task) error
-}
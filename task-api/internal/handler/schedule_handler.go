@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/schedule"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/db"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) CreateScheduleHandler(c *gin.Context) {
+	userID := c.GetUint64(UserIDKey)
+
+	var req createScheduleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid body of request"})
+		return
+	}
+
+	if !task.ValidateType(req.Type) {
+		h.logger.Info("invalid body of request", zap.Uint64("user_id", userID), zap.String("type", req.Type))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type of task"})
+		return
+	}
+
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+
+	nextRunAt, err := schedule.NextRun(req.CronExpr, req.Timezone, time.Now())
+	if err != nil {
+		h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID), zap.String("cron_expr", req.CronExpr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduleID, err := uuid.NewUUID()
+	if err != nil {
+		h.logger.Error("failed to generate schedule_id", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate schedule_id"})
+		return
+	}
+
+	renderedPayload, err := schedule.RenderPayload(req.Payload, schedule.TemplateData{
+		ScheduleID:  scheduleID,
+		ScheduledAt: nextRunAt,
+	})
+	if err != nil {
+		h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID), zap.Any("payload", req.Payload))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload template of task"})
+		return
+	}
+
+	if err := task.ValidatePayload(req.Type, renderedPayload); err != nil {
+		h.logger.Info("invalid body of request", zap.Uint64("user_id", userID), zap.Any("payload", req.Payload))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload of task"})
+		return
+	}
+
+	maxRetries, err := normalizeMaxRetries(req.MaxRetries)
+	if err != nil {
+		h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	retryPolicy, err := normalizeRetryPolicy(req.RetryPolicy)
+	if err != nil {
+		h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s := schedule.Schedule{
+		ID:          scheduleID,
+		UserID:      userID,
+		CronExpr:    req.CronExpr,
+		Timezone:    req.Timezone,
+		Type:        req.Type,
+		Payload:     req.Payload,
+		MaxRetries:  maxRetries,
+		RetryPolicy: retryPolicy,
+		NextRunAt:   nextRunAt,
+	}
+	createdSchedule, err := h.db.CreateSchedule(&s)
+	if err != nil {
+		h.logger.Error("failed to create schedule", zap.Error(err), zap.Uint64("user_id", userID), zap.String("schedule_id", scheduleID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule"})
+		return
+	}
+
+	h.logger.Info("successfully created schedule", zap.Uint64("user_id", userID), zap.String("schedule_id", scheduleID.String()))
+	c.JSON(http.StatusCreated, createdSchedule)
+}
+
+func (h *Handler) GetScheduleHandler(c *gin.Context) {
+	userID := c.GetUint64(UserIDKey)
+
+	rawScheduleID := c.Param("id")
+	scheduleID, ok := h.parseScheduleID(c, userID, rawScheduleID)
+	if !ok {
+		return
+	}
+
+	s, err := h.db.GetSchedule(userID, scheduleID)
+	if err != nil {
+		if errors.Is(err, db.ErrNoRows) {
+			h.logger.Info("incorrect schedule_id", zap.Uint64("user_id", userID), zap.String("schedule_id", rawScheduleID))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect schedule ID"})
+			return
+		}
+		h.logger.Error("failed to get schedule", zap.Error(err), zap.Uint64("user_id", userID), zap.String("schedule_id", rawScheduleID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get schedule"})
+		return
+	}
+
+	h.logger.Info("successfully get schedule", zap.Uint64("user_id", userID), zap.String("schedule_id", rawScheduleID))
+	c.JSON(http.StatusOK, s)
+}
+
+func (h *Handler) GetAllSchedulesHandler(c *gin.Context) {
+	userID := c.GetUint64(UserIDKey)
+
+	schedules, err := h.db.GetAllSchedules(userID)
+	if err != nil {
+		h.logger.Error("failed to get schedules", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get schedules"})
+		return
+	}
+
+	h.logger.Info("successfully get schedules", zap.Uint64("user_id", userID))
+	c.JSON(http.StatusOK, schedules)
+}
+
+func (h *Handler) DeleteScheduleHandler(c *gin.Context) {
+	userID := c.GetUint64(UserIDKey)
+
+	rawScheduleID := c.Param("id")
+	scheduleID, ok := h.parseScheduleID(c, userID, rawScheduleID)
+	if !ok {
+		return
+	}
+
+	s, err := h.db.DeleteSchedule(userID, scheduleID)
+	if err != nil {
+		if errors.Is(err, db.ErrNoRows) {
+			h.logger.Info("incorrect schedule_id", zap.Uint64("user_id", userID), zap.String("schedule_id", rawScheduleID))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect schedule ID"})
+			return
+		}
+		h.logger.Error("failed to delete schedule", zap.Error(err), zap.Uint64("user_id", userID), zap.String("schedule_id", rawScheduleID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete schedule"})
+		return
+	}
+
+	h.logger.Info("successfully delete schedule", zap.Uint64("user_id", userID), zap.String("schedule_id", rawScheduleID))
+	c.JSON(http.StatusOK, s)
+}
+
+func (h *Handler) parseScheduleID(c *gin.Context, userID uint64, rawScheduleID string) (uuid.UUID, bool) {
+	scheduleID, err := uuid.Parse(rawScheduleID)
+	if err != nil {
+		h.logger.Error("failed to parse schedule_id", zap.Error(err), zap.Uint64("user_id", userID), zap.String("schedule_id", rawScheduleID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse schedule ID"})
+		return uuid.Nil, false
+	}
+
+	return scheduleID, true
+}
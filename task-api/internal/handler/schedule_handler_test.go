@@ -0,0 +1,350 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/schedule"
+	pdb "github.com/imightbuyaboat/TaskFlow/task-api/internal/db"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCreateScheduleHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	logger := zaptest.NewLogger(t)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	nextRunAt := time.Now().Add(time.Hour).UTC()
+	createdAt := time.Now().UTC()
+
+	createdSchedule := schedule.Schedule{
+		ID:       uuid.New(),
+		UserID:   1,
+		CronExpr: "0 9 * * *",
+		Timezone: "Europe/Moscow",
+		Type:     "send_email",
+		Payload: map[string]interface{}{
+			"to":      "test@test.com",
+			"subject": "Digest for {{.ScheduledAt.Format \"2006-01-02\"}}",
+		},
+		MaxRetries: 3,
+		NextRunAt:  nextRunAt,
+		CreatedAt:  createdAt,
+	}
+
+	tests := []struct {
+		name           string
+		body           interface{}
+		mockBDSetup    func(db *mocks.MockDB)
+		expectedStatus int
+		expectedBody   gin.H
+	}{
+		{
+			name: "Successfully schedule creation",
+			body: createScheduleReq{
+				CronExpr: createdSchedule.CronExpr,
+				Timezone: createdSchedule.Timezone,
+				Type:     createdSchedule.Type,
+				Payload:  createdSchedule.Payload,
+			},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateSchedule(gomock.Any()).DoAndReturn(func(s *schedule.Schedule) (*schedule.Schedule, error) {
+					assert.Equal(t, uint8(3), s.MaxRetries)
+					assert.True(t, s.NextRunAt.After(time.Now()))
+					return &createdSchedule, nil
+				})
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody: gin.H{
+				"id":           createdSchedule.ID.String(),
+				"user_id":      float64(createdSchedule.UserID),
+				"cron_expr":    createdSchedule.CronExpr,
+				"timezone":     createdSchedule.Timezone,
+				"type":         createdSchedule.Type,
+				"payload":      createdSchedule.Payload,
+				"max_retries":  float64(createdSchedule.MaxRetries),
+				"retry_policy": nil,
+				"next_run_at":  createdSchedule.NextRunAt.Format(time.RFC3339Nano),
+				"created_at":   createdSchedule.CreatedAt.Format(time.RFC3339Nano),
+			},
+		},
+		{
+			name: "Invalid body of request",
+			body: createScheduleReq{
+				Type: "send_email",
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "Invalid body of request"},
+		},
+		{
+			name: "Invalid type of task",
+			body: createScheduleReq{
+				CronExpr: "@daily",
+				Type:     "invalid type",
+				Payload:  map[string]interface{}{"urls": []string{"https://go.dev/"}},
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "invalid type of task"},
+		},
+		{
+			name: "Invalid cron expression",
+			body: createScheduleReq{
+				CronExpr: "every day",
+				Type:     "download_files",
+				Payload:  map[string]interface{}{"urls": []string{"https://go.dev/"}},
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "invalid cron expression: expected exactly 5 fields, found 2: [every day]"},
+		},
+		{
+			name: "Invalid timezone",
+			body: createScheduleReq{
+				CronExpr: "@daily",
+				Timezone: "Mars/Olympus",
+				Type:     "download_files",
+				Payload:  map[string]interface{}{"urls": []string{"https://go.dev/"}},
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "invalid timezone: unknown time zone Mars/Olympus"},
+		},
+		{
+			name: "Invalid payload template",
+			body: createScheduleReq{
+				CronExpr: "@daily",
+				Type:     "send_email",
+				Payload: map[string]interface{}{
+					"to":      "test@test.com",
+					"subject": "{{.Unknown}}",
+				},
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "invalid payload template of task"},
+		},
+		{
+			name: "Invalid payload of task",
+			body: createScheduleReq{
+				CronExpr: "@daily",
+				Type:     "send_email",
+				Payload:  map[string]interface{}{"to": "invalid email"},
+			},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "invalid payload of task"},
+		},
+		{
+			name: "db error",
+			body: createScheduleReq{
+				CronExpr: "@daily",
+				Type:     "download_files",
+				Payload:  map[string]interface{}{"urls": []string{"https://go.dev/"}},
+			},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateSchedule(gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to create schedule"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBDSetup(mockDB)
+
+			bodyBytes, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/schedules", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			h.CreateScheduleHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var responseBody gin.H
+			if err := json.Unmarshal(w.Body.Bytes(), &responseBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedBody, responseBody)
+		})
+	}
+}
+
+func TestGetAllSchedulesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	logger := zaptest.NewLogger(t)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	tests := []struct {
+		name           string
+		mockBDSetup    func(db *mocks.MockDB)
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name: "Successfully get schedules",
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetAllSchedules(gomock.Any()).Return([]schedule.Schedule{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []interface{}{},
+		},
+		{
+			name: "db error",
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetAllSchedules(gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   map[string]interface{}{"error": "Failed to get schedules"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBDSetup(mockDB)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/schedules", nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			h.GetAllSchedulesHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var responseBody interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &responseBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedBody, responseBody)
+		})
+	}
+}
+
+func TestDeleteScheduleHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	logger := zaptest.NewLogger(t)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	scheduleID := uuid.New()
+	nextRunAt := time.Now().Add(time.Hour).UTC()
+	createdAt := time.Now().UTC()
+
+	deletedSchedule := schedule.Schedule{
+		ID:         scheduleID,
+		UserID:     1,
+		CronExpr:   "@daily",
+		Timezone:   "UTC",
+		Type:       "download_files",
+		Payload:    map[string]interface{}{"urls": []interface{}{"https://go.dev/"}},
+		MaxRetries: 3,
+		NextRunAt:  nextRunAt,
+		CreatedAt:  createdAt,
+	}
+
+	tests := []struct {
+		name           string
+		scheduleIDStr  string
+		mockBDSetup    func(db *mocks.MockDB)
+		expectedStatus int
+		expectedBody   gin.H
+	}{
+		{
+			name:          "Successfully delete schedule",
+			scheduleIDStr: scheduleID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().DeleteSchedule(gomock.Any(), scheduleID).Return(&deletedSchedule, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: gin.H{
+				"id":           deletedSchedule.ID.String(),
+				"user_id":      float64(deletedSchedule.UserID),
+				"cron_expr":    deletedSchedule.CronExpr,
+				"timezone":     deletedSchedule.Timezone,
+				"type":         deletedSchedule.Type,
+				"payload":      deletedSchedule.Payload,
+				"max_retries":  float64(deletedSchedule.MaxRetries),
+				"retry_policy": nil,
+				"next_run_at":  deletedSchedule.NextRunAt.Format(time.RFC3339Nano),
+				"created_at":   deletedSchedule.CreatedAt.Format(time.RFC3339Nano),
+			},
+		},
+		{
+			name:           "Failed to parse schedule_id",
+			scheduleIDStr:  "invalid id",
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to parse schedule ID"},
+		},
+		{
+			name:          "Incorrect schedule_id",
+			scheduleIDStr: uuid.New().String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().DeleteSchedule(gomock.Any(), gomock.Any()).Return(nil, pdb.ErrNoRows)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "Incorrect schedule ID"},
+		},
+		{
+			name:          "db error",
+			scheduleIDStr: scheduleID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().DeleteSchedule(gomock.Any(), scheduleID).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to delete schedule"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBDSetup(mockDB)
+
+			req, _ := http.NewRequest(http.MethodDelete, "/api/schedules/"+tt.scheduleIDStr, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = gin.Params{gin.Param{
+				Key:   "id",
+				Value: tt.scheduleIDStr,
+			}}
+
+			h.DeleteScheduleHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var responseBody gin.H
+			if err := json.Unmarshal(w.Body.Bytes(), &responseBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedBody, responseBody)
+		})
+	}
+}
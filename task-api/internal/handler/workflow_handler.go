@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/db"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) CreateWorkflowHandler(c *gin.Context) {
+	userID := c.GetUint64(UserIDKey)
+
+	var req createWorkflowReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid body of request"})
+		return
+	}
+
+	sorted, err := req.order()
+	if err != nil {
+		h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workflowID, err := uuid.NewUUID()
+	if err != nil {
+		h.logger.Error("failed to generate workflow_id", zap.Error(err), zap.Uint64("user_id", userID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate workflow_id"})
+		return
+	}
+
+	w := task.Workflow{
+		ID:     workflowID,
+		UserID: userID,
+		Tasks:  make(map[string]task.Task, len(req.Tasks)),
+	}
+	order := make([]string, 0, len(sorted))
+	for _, r := range sorted {
+		t, err := newWorkflowTask(userID, r, w.Tasks)
+		if err != nil {
+			h.logger.Info("invalid body of request", zap.Error(err), zap.Uint64("user_id", userID), zap.String("name", r.Name))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		w.Tasks[r.Name] = *t
+		order = append(order, r.Name)
+	}
+
+	createdWorkflow, err := h.db.CreateWorkflow(&w, order)
+	if err != nil {
+		h.logger.Error("failed to create workflow", zap.Error(err), zap.Uint64("user_id", userID), zap.String("workflow_id", workflowID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workflow"})
+		return
+	}
+
+	for _, t := range createdWorkflow.Tasks {
+		metrics.TasksCreated.WithLabelValues(t.Type).Inc()
+	}
+
+	h.logger.Info("successfully created workflow", zap.Uint64("user_id", userID), zap.String("workflow_id", workflowID.String()))
+	c.JSON(http.StatusCreated, createdWorkflow)
+}
+
+func (h *Handler) GetWorkflowHandler(c *gin.Context) {
+	userID := c.GetUint64(UserIDKey)
+
+	rawWorkflowID := c.Param("id")
+	workflowID, err := uuid.Parse(rawWorkflowID)
+	if err != nil {
+		h.logger.Error("failed to parse workflow_id", zap.Error(err), zap.Uint64("user_id", userID), zap.String("workflow_id", rawWorkflowID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse workflow ID"})
+		return
+	}
+
+	w, err := h.db.GetWorkflow(userID, workflowID)
+	if err != nil {
+		if errors.Is(err, db.ErrNoRows) {
+			h.logger.Info("incorrect workflow_id", zap.Uint64("user_id", userID), zap.String("workflow_id", rawWorkflowID))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect workflow ID"})
+			return
+		}
+		h.logger.Error("failed to get workflow", zap.Error(err), zap.Uint64("user_id", userID), zap.String("workflow_id", rawWorkflowID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workflow"})
+		return
+	}
+
+	h.logger.Info("successfully get workflow", zap.Uint64("user_id", userID), zap.String("workflow_id", rawWorkflowID))
+	c.JSON(http.StatusOK, w)
+}
+
+func newWorkflowTask(userID uint64, r *workflowTaskReq, created map[string]task.Task) (*task.Task, error) {
+	var dependsOn []uuid.UUID
+	parentTypes := make(map[string]string, len(r.DependsOn))
+	for _, parent := range r.DependsOn {
+		dependsOn = append(dependsOn, created[parent].ID)
+		parentTypes[parent] = created[parent].Type
+	}
+
+	req := createTaskReq{
+		Type:        r.Type,
+		Payload:     r.Payload,
+		MaxRetries:  r.MaxRetries,
+		RunAt:       r.RunAt,
+		RetryPolicy: r.RetryPolicy,
+		DependsOn:   dependsOn,
+		Priority:    r.Priority,
+	}
+
+	t, err := req.toTask(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := task.ValidateReferences(t.Type, t.Payload, parentTypes); err != nil {
+		return nil, err
+	}
+
+	t.Payload = task.RenameReferences(t.Payload, func(name string) (string, bool) {
+		parent, ok := created[name]
+		return parent.ID.String(), ok
+	})
+
+	t.ID, err = uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	pdb "github.com/imightbuyaboat/TaskFlow/task-api/internal/db"
+	"github.com/imightbuyaboat/TaskFlow/task-api/internal/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCreateWorkflowHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	logger := zaptest.NewLogger(t)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	downloadPayload := map[string]interface{}{"urls": []interface{}{"https://go.dev/images/gophers/ladder.svg"}}
+	emailPayload := map[string]interface{}{"to": "test@test.com", "subject": "done"}
+
+	createdAt := time.Now().UTC()
+	downloadTask := task.Task{
+		ID:         uuid.New(),
+		UserID:     1,
+		Type:       "download_files",
+		Payload:    downloadPayload,
+		Status:     "queued",
+		MaxRetries: 3,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+	emailTask := task.Task{
+		ID:         uuid.New(),
+		UserID:     1,
+		Type:       "send_email",
+		Payload:    emailPayload,
+		Status:     "waiting",
+		MaxRetries: 3,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+		DependsOn:  []uuid.UUID{downloadTask.ID},
+	}
+	createdWorkflow := task.Workflow{
+		ID:        uuid.New(),
+		UserID:    1,
+		CreatedAt: createdAt,
+		Tasks: map[string]task.Task{
+			"download": downloadTask,
+			"email":    emailTask,
+		},
+	}
+
+	tests := []struct {
+		name           string
+		body           interface{}
+		mockBDSetup    func(db *mocks.MockDB)
+		expectedStatus int
+		expectedBody   gin.H
+	}{
+		{
+			name: "Successfully workflow creation",
+			body: createWorkflowReq{Tasks: []workflowTaskReq{
+				{Name: "email", Type: "send_email", Payload: emailPayload, DependsOn: []string{"download"}},
+				{Name: "download", Type: "download_files", Payload: downloadPayload},
+			}},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateWorkflow(gomock.Any(), []string{"download", "email"}).DoAndReturn(func(w *task.Workflow, order []string) (*task.Workflow, error) {
+					assert.Equal(t, []uuid.UUID{w.Tasks["download"].ID}, w.Tasks["email"].DependsOn)
+					assert.Nil(t, w.Tasks["download"].DependsOn)
+					return &createdWorkflow, nil
+				})
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody: gin.H{
+				"id":         createdWorkflow.ID.String(),
+				"user_id":    float64(1),
+				"created_at": createdAt.Format(time.RFC3339Nano),
+				"tasks": map[string]interface{}{
+					"download": map[string]interface{}{
+						"id":           downloadTask.ID.String(),
+						"user_id":      float64(1),
+						"type":         "download_files",
+						"payload":      downloadPayload,
+						"status":       "queued",
+						"retries":      float64(0),
+						"max_retries":  float64(3),
+						"run_at":       nil,
+						"created_at":   createdAt.Format(time.RFC3339Nano),
+						"updated_at":   createdAt.Format(time.RFC3339Nano),
+						"retry_policy": nil,
+						"priority":     float64(0),
+					},
+					"email": map[string]interface{}{
+						"id":           emailTask.ID.String(),
+						"user_id":      float64(1),
+						"type":         "send_email",
+						"payload":      emailPayload,
+						"status":       "waiting",
+						"retries":      float64(0),
+						"max_retries":  float64(3),
+						"run_at":       nil,
+						"created_at":   createdAt.Format(time.RFC3339Nano),
+						"updated_at":   createdAt.Format(time.RFC3339Nano),
+						"retry_policy": nil,
+						"priority":     float64(0),
+						"depends_on":   []interface{}{downloadTask.ID.String()},
+					},
+				},
+			},
+		},
+		{
+			name:           "Empty workflow",
+			body:           createWorkflowReq{Tasks: []workflowTaskReq{}},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "workflow should contain between 1 and 100 tasks"},
+		},
+		{
+			name: "Duplicate task name",
+			body: createWorkflowReq{Tasks: []workflowTaskReq{
+				{Name: "download", Type: "download_files", Payload: downloadPayload},
+				{Name: "download", Type: "download_files", Payload: downloadPayload},
+			}},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "duplicate task name \"download\""},
+		},
+		{
+			name: "Unknown dependency",
+			body: createWorkflowReq{Tasks: []workflowTaskReq{
+				{Name: "email", Type: "send_email", Payload: emailPayload, DependsOn: []string{"download"}},
+			}},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "unknown dependency \"download\" of task \"email\""},
+		},
+		{
+			name: "Workflow with cycle",
+			body: createWorkflowReq{Tasks: []workflowTaskReq{
+				{Name: "a", Type: "send_email", Payload: emailPayload, DependsOn: []string{"b"}},
+				{Name: "b", Type: "send_email", Payload: emailPayload, DependsOn: []string{"a"}},
+			}},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "workflow contains a cycle"},
+		},
+		{
+			name: "References to outputs of parent tasks",
+			body: createWorkflowReq{Tasks: []workflowTaskReq{
+				{Name: "download", Type: "download_files", Payload: downloadPayload},
+				{Name: "resize", Type: "process_image", Payload: map[string]interface{}{"path": "{{ download.paths.0 }}"}, DependsOn: []string{"download"}},
+				{Name: "notify", Type: "send_email", Payload: map[string]interface{}{
+					"to":             "test@test.com",
+					"attached_files": []interface{}{"{{ resize.path }}"},
+				}, DependsOn: []string{"resize"}},
+			}},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateWorkflow(gomock.Any(), []string{"download", "resize", "notify"}).DoAndReturn(func(w *task.Workflow, order []string) (*task.Workflow, error) {
+					assert.Equal(t, "{{ "+w.Tasks["download"].ID.String()+".paths.0 }}", w.Tasks["resize"].Payload["path"])
+					assert.Equal(t, []interface{}{"{{ " + w.Tasks["resize"].ID.String() + ".path }}"}, w.Tasks["notify"].Payload["attached_files"])
+					return nil, errors.New("db error")
+				})
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to create workflow"},
+		},
+		{
+			name: "Reference of wrong type",
+			body: createWorkflowReq{Tasks: []workflowTaskReq{
+				{Name: "download", Type: "download_files", Payload: downloadPayload},
+				{Name: "resize", Type: "process_image", Payload: map[string]interface{}{"path": "{{ download.paths }}"}, DependsOn: []string{"download"}},
+			}},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "reference in field \"path\" of payload has wrong type"},
+		},
+		{
+			name: "Reference to task outside of depends_on",
+			body: createWorkflowReq{Tasks: []workflowTaskReq{
+				{Name: "download", Type: "download_files", Payload: downloadPayload},
+				{Name: "resize", Type: "process_image", Payload: map[string]interface{}{"path": "{{ download.paths.0 }}"}},
+			}},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "payload references task \"download\" that is not in depends_on"},
+		},
+		{
+			name: "Invalid payload of task",
+			body: createWorkflowReq{Tasks: []workflowTaskReq{
+				{Name: "email", Type: "send_email", Payload: map[string]interface{}{"to": "invalid email"}},
+			}},
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "invalid payload of task"},
+		},
+		{
+			name: "db error",
+			body: createWorkflowReq{Tasks: []workflowTaskReq{
+				{Name: "download", Type: "download_files", Payload: downloadPayload},
+			}},
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().CreateWorkflow(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to create workflow"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBDSetup(mockDB)
+
+			bodyBytes, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/workflows", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			h.CreateWorkflowHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var responseBody gin.H
+			if err := json.Unmarshal(w.Body.Bytes(), &responseBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedBody, responseBody)
+		})
+	}
+}
+
+func TestGetWorkflowHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	logger := zaptest.NewLogger(t)
+	h, _ := NewHandler(mockDB, nil, logger)
+
+	workflowID := uuid.New()
+	createdAt := time.Now().UTC()
+
+	tests := []struct {
+		name           string
+		workflowIDStr  string
+		mockBDSetup    func(db *mocks.MockDB)
+		expectedStatus int
+		expectedBody   gin.H
+	}{
+		{
+			name:          "Successfully get workflow",
+			workflowIDStr: workflowID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetWorkflow(gomock.Any(), workflowID).Return(&task.Workflow{
+					ID:        workflowID,
+					UserID:    1,
+					CreatedAt: createdAt,
+					Tasks:     map[string]task.Task{},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: gin.H{
+				"id":         workflowID.String(),
+				"user_id":    float64(1),
+				"created_at": createdAt.Format(time.RFC3339Nano),
+				"tasks":      map[string]interface{}{},
+			},
+		},
+		{
+			name:           "Failed to parse workflow_id",
+			workflowIDStr:  "invalid id",
+			mockBDSetup:    func(db *mocks.MockDB) {},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to parse workflow ID"},
+		},
+		{
+			name:          "Incorrect workflow_id",
+			workflowIDStr: workflowID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetWorkflow(gomock.Any(), workflowID).Return(nil, pdb.ErrNoRows)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   gin.H{"error": "Incorrect workflow ID"},
+		},
+		{
+			name:          "db error",
+			workflowIDStr: workflowID.String(),
+			mockBDSetup: func(db *mocks.MockDB) {
+				db.EXPECT().GetWorkflow(gomock.Any(), workflowID).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   gin.H{"error": "Failed to get workflow"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBDSetup(mockDB)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/workflows/"+tt.workflowIDStr, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = gin.Params{gin.Param{
+				Key:   "id",
+				Value: tt.workflowIDStr,
+			}}
+
+			h.GetWorkflowHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var responseBody gin.H
+			if err := json.Unmarshal(w.Body.Bytes(), &responseBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedBody, responseBody)
+		})
+	}
+}
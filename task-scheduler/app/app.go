@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/health"
+	"github.com/imightbuyaboat/TaskFlow/pkg/queue"
+	"github.com/imightbuyaboat/TaskFlow/task-scheduler/internal/scheduler"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	DatabaseURL     string
+	Interval        time.Duration
+	OutboxInterval  time.Duration
+	OutboxBatchSize int
+	HealthPrefix    string
+}
+
+type App struct {
+	db        database
+	scheduler *scheduler.Scheduler
+	relay     *scheduler.Relay
+	logger    *zap.Logger
+}
+
+func New(cfg Config, q queue.Queue, checker *health.Checker, logger *zap.Logger) (*App, error) {
+	db, err := openDB(cfg.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to db: %v", err)
+	}
+
+	s, err := scheduler.NewScheduler(cfg.Interval, db, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create scheduler: %v", err)
+	}
+
+	r, err := scheduler.NewRelay(cfg.OutboxInterval, cfg.OutboxBatchSize, db, q, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create relay: %v", err)
+	}
+
+	checker.AddReadiness(cfg.HealthPrefix+"db", db.Ping)
+
+	return &App{
+		db:        db,
+		scheduler: s,
+		relay:     r,
+		logger:    logger,
+	}, nil
+}
+
+func (a *App) Run(ctx context.Context) {
+	defer a.db.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		a.relay.EnterLoop(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		a.scheduler.EnterLoop(ctx)
+	}()
+
+	<-ctx.Done()
+	a.logger.Info("shutting down scheduler")
+
+	wg.Wait()
+}
@@ -1,16 +1,19 @@
 package main
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"net/http"
 	"os"
-	"time"
+	"os/signal"
+	"strconv"
+	"syscall"
 
+	"github.com/imightbuyaboat/TaskFlow/pkg/config"
+	"github.com/imightbuyaboat/TaskFlow/pkg/health"
 	"github.com/imightbuyaboat/TaskFlow/pkg/logger"
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
 	"github.com/imightbuyaboat/TaskFlow/pkg/queue"
-	"github.com/imightbuyaboat/TaskFlow/task-scheduler/internal/db"
-	"github.com/imightbuyaboat/TaskFlow/task-scheduler/internal/scheduler"
-	"github.com/joho/godotenv"
+	"github.com/imightbuyaboat/TaskFlow/task-scheduler/app"
 	"go.uber.org/zap"
 )
 
@@ -18,54 +21,54 @@ func main() {
 	logger.InitLogger()
 	log := logger.GetLogger()
 
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("failed to load .env file", zap.Error(err))
+	cfg := config.Default()
+	if _, err := cfg.Load(os.Args[0], os.Args[1:]); err != nil {
+		log.Fatal("failed to load config", zap.Error(err))
 	}
 
-	f, err := os.Open("config.json")
-	if err != nil {
-		log.Fatal("failed to open confug file", zap.Error(err))
-	}
-
-	var configsFromFile struct {
-		IntervalMs int `json:"schedulerIntervalMs"`
+	if err := logger.SetLevel(cfg.Log.Level); err != nil {
+		log.Fatal("failed to set log level", zap.Error(err))
 	}
 
-	if err = json.NewDecoder(f).Decode(&configsFromFile); err != nil {
-		log.Fatal("failed to decode configs from file", zap.Error(err))
-	}
+	log.Info("loaded config", zap.Any("config", cfg.Redacted()))
 
-	if configsFromFile.IntervalMs <= 0 {
-		log.Fatal("invalid configs in file", zap.Int("interval", configsFromFile.IntervalMs))
+	queue, err := queue.NewQueue(cfg.Queue.Backend, cfg.AMQP.URL(), cfg.DatabaseURL(), log)
+	if err != nil {
+		log.Fatal("failed to connect to queue", zap.Error(err))
 	}
 
-	postgresURL := fmt.Sprintf("postgres://%s:%s@db:%s/%s",
-		os.Getenv("POSTGRES_USER"), os.Getenv("POSTGRES_PASSWORD"),
-		os.Getenv("POSTGRES_PORT"), os.Getenv("POSTGRES_DB"))
+	checker := health.NewChecker()
+	checker.AddReadiness("queue", queue.Check)
 
-	db, err := db.NewPostgresDB(postgresURL)
+	a, err := app.New(app.Config{
+		DatabaseURL:     cfg.DatabaseURL(),
+		Interval:        cfg.Scheduler.Interval,
+		OutboxInterval:  cfg.Scheduler.OutboxInterval,
+		OutboxBatchSize: cfg.Scheduler.OutboxBatchSize,
+	}, queue, checker, log)
 	if err != nil {
-		log.Fatal("failed to connect to db", zap.Error(err))
+		log.Fatal("failed to create scheduler", zap.Error(err))
 	}
 
-	amqpURL := fmt.Sprintf("amqp://%s:%s@rabbitmq:%s/",
-		os.Getenv("AMQP_USER"), os.Getenv("AMQP_PASSWORD"),
-		os.Getenv("AMQP_PORT"))
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", checker.LivenessHandler())
+	mux.Handle("/readyz", checker.ReadinessHandler())
 
-	queue, err := queue.NewRabbitMQQueue(amqpURL)
-	if err != nil {
-		log.Fatal("failed to connect to queue", zap.Error(err))
-	}
+	go func() {
+		if err := http.ListenAndServe(":"+strconv.Itoa(cfg.Metrics.Port), mux); err != nil {
+			log.Fatal("failed to start probes server", zap.Error(err))
+		}
+	}()
 
-	s, err := scheduler.NewScheduler(
-		time.Duration(configsFromFile.IntervalMs)*time.Millisecond,
-		db,
-		queue,
-		log,
-	)
-	if err != nil {
-		log.Fatal("failed to create scheduler", zap.Error(err))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	a.Run(ctx)
+
+	if err := queue.Close(); err != nil {
+		log.Error("failed to close queue", zap.Error(err))
 	}
 
-	s.EnterLoop()
+	log.Info("scheduler gracefully stopped")
 }
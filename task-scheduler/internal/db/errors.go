@@ -0,0 +1,5 @@
+package db
+
+import "errors"
+
+var ErrScheduleAlreadyMaterialized = errors.New("schedule already materialized")
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+type OutboxMessage struct {
+	ID   uint64
+	Task task.Task
+}
+
+func (db *PostgresDB) ClaimOutbox(limit int, lease time.Duration) ([]OutboxMessage, error) {
+	query := `with claimed as (
+		update outbox set locked_until = now() + @lease_ms * interval '1 millisecond'
+		where id in (
+			select id from outbox
+			where sent_at is null and (locked_until is null or locked_until < now())
+			order by id
+			limit @limit
+			for update skip locked
+		)
+		returning id, task_id
+	)
+	select c.id, t.id, t.user_id, t.type, t.payload, t.status, t.retries,
+	t.max_retries, t.run_at, t.created_at, t.updated_at, t.retry_policy, t.priority
+	from claimed c join tasks t on t.id = c.task_id
+	order by c.id`
+	args := pgx.NamedArgs{
+		"lease_ms": lease.Milliseconds(),
+		"limit":    limit,
+	}
+
+	rows, err := db.Query(db.ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %v", err)
+	}
+	defer rows.Close()
+
+	messages := []OutboxMessage{}
+	for rows.Next() {
+		m := OutboxMessage{}
+		err := rows.Scan(
+			&m.ID, &m.Task.ID, &m.Task.UserID, &m.Task.Type, &m.Task.Payload,
+			&m.Task.Status, &m.Task.Retries, &m.Task.MaxRetries,
+			&m.Task.RunAt, &m.Task.CreatedAt, &m.Task.UpdatedAt, &m.Task.RetryPolicy, &m.Task.Priority,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %v", err)
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %v", err)
+	}
+
+	return messages, nil
+}
+
+func (db *PostgresDB) MarkOutboxSent(ids []uint64) error {
+	query := "update outbox set sent_at = now() where id = any(@ids)"
+	args := pgx.NamedArgs{
+		"ids": ids,
+	}
+
+	if _, err := db.Exec(db.ctx, query, args); err != nil {
+		return fmt.Errorf("failed to mark outbox messages as sent: %v", err)
+	}
+
+	return nil
+}
+
+func (db *PostgresDB) DeleteSentOutbox(olderThan time.Duration) (int64, error) {
+	query := "delete from outbox where sent_at < now() - @older_than_ms * interval '1 millisecond'"
+	args := pgx.NamedArgs{
+		"older_than_ms": olderThan.Milliseconds(),
+	}
+
+	tag, err := db.Exec(db.ctx, query, args)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sent outbox messages: %v", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -3,13 +3,12 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/imightbuyaboat/TaskFlow/pkg/postgres"
-	"github.com/imightbuyaboat/TaskFlow/pkg/task"
 )
 
 type PostgresDB struct {
@@ -28,47 +27,52 @@ func NewPostgresDB(postgresURL string) (*PostgresDB, error) {
 	return &PostgresDB{pool, ctx}, nil
 }
 
-func (db *PostgresDB) GetPostponedTasks() ([]task.Task, error) {
-	query := `select * from tasks
-	where (status = 'postponed' and now() >= run_at)
-	or (status = 'queued' and extract(hour from (now() - run_at)) > 1)`
+func (db *PostgresDB) EnqueuePostponedTasks() (int64, error) {
+	query := `with due as (
+		update tasks set status = 'queued'
+		where status = 'postponed' and now() >= run_at
+		returning id, priority, run_at
+	)
+	insert into outbox (task_id) select id from due order by priority desc, run_at, id`
 
-	rows, err := db.Query(db.ctx, query)
+	tag, err := db.Exec(db.ctx, query)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return []task.Task{}, nil
-		}
-		return nil, fmt.Errorf("failed to select postponed tasks: %v", err)
+		return 0, fmt.Errorf("failed to enqueue postponed tasks: %v", err)
 	}
 
-	tasks := []task.Task{}
-	for rows.Next() {
-		t := task.Task{}
-		err := rows.Scan(
-			&t.ID, &t.UserID, &t.Type, &t.Payload,
-			&t.Status, &t.Retries, &t.MaxRetries,
-			&t.RunAt, &t.CreatedAt, &t.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %v", err)
-		}
-		tasks = append(tasks, t)
+	return tag.RowsAffected(), nil
+}
+
+func (db *PostgresDB) RequeueStaleTasks(olderThan time.Duration) (int64, error) {
+	query := `insert into outbox (task_id) select id from tasks t
+	where status = 'queued' and run_at < now() - @older_than_ms * interval '1 millisecond'
+	and not exists (
+		select 1 from outbox o where o.task_id = t.id
+		and (o.sent_at is null or o.created_at >= now() - @older_than_ms * interval '1 millisecond')
+	)
+	order by priority desc, run_at, id`
+	args := pgx.NamedArgs{
+		"older_than_ms": olderThan.Milliseconds(),
+	}
+
+	tag, err := db.Exec(db.ctx, query, args)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale tasks: %v", err)
 	}
 
-	return tasks, nil
+	return tag.RowsAffected(), nil
 }
 
-func (db *PostgresDB) UpdateStatusOfTask(taskID uuid.UUID, status string) error {
-	query := "update tasks set status = @status where id = @task_id"
+func (db *PostgresDB) DeleteExpiredIdempotencyKeys(olderThan time.Duration) (int64, error) {
+	query := "delete from idempotency_keys where created_at < now() - @older_than_ms * interval '1 millisecond'"
 	args := pgx.NamedArgs{
-		"status":  status,
-		"task_id": taskID,
+		"older_than_ms": olderThan.Milliseconds(),
 	}
 
-	_, err := db.Exec(db.ctx, query, args)
+	tag, err := db.Exec(db.ctx, query, args)
 	if err != nil {
-		return fmt.Errorf("failed to update task status: %v", err)
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %v", err)
 	}
 
-	return nil
+	return tag.RowsAffected(), nil
 }
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/schedule"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+func (db *PostgresDB) GetDueSchedules() ([]schedule.Schedule, error) {
+	query := "select * from schedules where next_run_at <= now() order by next_run_at"
+
+	rows, err := db.Query(db.ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select due schedules: %v", err)
+	}
+	defer rows.Close()
+
+	schedules := []schedule.Schedule{}
+	for rows.Next() {
+		s := schedule.Schedule{}
+		err := rows.Scan(
+			&s.ID, &s.UserID, &s.CronExpr, &s.Timezone, &s.Type, &s.Payload,
+			&s.MaxRetries, &s.RetryPolicy, &s.NextRunAt, &s.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %v", err)
+		}
+		schedules = append(schedules, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to select due schedules: %v", err)
+	}
+
+	return schedules, nil
+}
+
+func (db *PostgresDB) MaterializeSchedule(s *schedule.Schedule, t *task.Task, nextRunAt time.Time) (*task.Task, error) {
+	tx, err := db.Begin(db.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(db.ctx)
+
+	query := "update schedules set next_run_at = @next_run_at where id = @schedule_id and next_run_at = @prev_run_at"
+	args := pgx.NamedArgs{
+		"next_run_at": nextRunAt,
+		"schedule_id": s.ID,
+		"prev_run_at": s.NextRunAt,
+	}
+
+	tag, err := tx.Exec(db.ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update next run of schedule: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrScheduleAlreadyMaterialized
+	}
+
+	query = `insert into tasks (id, user_id, type, payload, status, max_retries, run_at, retry_policy)
+	values (@id, @user_id, @type, @payload, 'queued', @max_retries, @run_at, @retry_policy)
+	returning *`
+	args = pgx.NamedArgs{
+		"id":           t.ID,
+		"user_id":      t.UserID,
+		"type":         t.Type,
+		"payload":      t.Payload,
+		"max_retries":  t.MaxRetries,
+		"run_at":       t.RunAt,
+		"retry_policy": t.RetryPolicy,
+	}
+
+	createdTask := &task.Task{}
+	err = tx.QueryRow(db.ctx, query, args).Scan(
+		&createdTask.ID, &createdTask.UserID, &createdTask.Type, &createdTask.Payload,
+		&createdTask.Status, &createdTask.Retries, &createdTask.MaxRetries,
+		&createdTask.RunAt, &createdTask.CreatedAt, &createdTask.UpdatedAt, &createdTask.RetryPolicy, &createdTask.Result, &createdTask.Priority,
+		&createdTask.UniqueKey, &createdTask.UniqueUntil, &createdTask.UniqueActive,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert task: %v", err)
+	}
+
+	query = "insert into outbox (task_id) values (@task_id)"
+	args = pgx.NamedArgs{
+		"task_id": createdTask.ID,
+	}
+	if _, err := tx.Exec(db.ctx, query, args); err != nil {
+		return nil, fmt.Errorf("failed to insert task into outbox: %v", err)
+	}
+
+	if err := tx.Commit(db.ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return createdTask, nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+)
+
+func (db *SQLiteDB) ClaimOutbox(limit int, lease time.Duration) ([]OutboxMessage, error) {
+	tx, err := db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	query := `select o.id, t.id, t.user_id, t.type, t.payload, t.status, t.retries,
+	t.max_retries, t.run_at, t.created_at, t.updated_at, t.retry_policy, t.priority
+	from outbox o join tasks t on t.id = o.task_id
+	where o.sent_at is null and (o.locked_until is null or o.locked_until < @now)
+	order by o.id
+	limit @limit`
+
+	rows, err := tx.QueryContext(db.ctx, query,
+		sql.Named("now", sqlite.Time(now)),
+		sql.Named("limit", limit),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %v", err)
+	}
+	defer rows.Close()
+
+	messages := []OutboxMessage{}
+	ids := []uint64{}
+	for rows.Next() {
+		m := OutboxMessage{}
+		err := rows.Scan(
+			&m.ID, &m.Task.ID, &m.Task.UserID, &m.Task.Type, sqlite.JSON{V: &m.Task.Payload},
+			&m.Task.Status, &m.Task.Retries, &m.Task.MaxRetries,
+			&m.Task.RunAt, &m.Task.CreatedAt, &m.Task.UpdatedAt, sqlite.JSON{V: &m.Task.RetryPolicy}, &m.Task.Priority,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %v", err)
+		}
+		messages = append(messages, m)
+		ids = append(ids, m.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %v", err)
+	}
+
+	if len(ids) == 0 {
+		return messages, nil
+	}
+
+	query = "update outbox set locked_until = @locked_until where id in (select value from json_each(@ids))"
+	_, err = tx.ExecContext(db.ctx, query,
+		sql.Named("locked_until", sqlite.Time(now.Add(lease))),
+		sql.Named("ids", sqlite.JSON{V: ids}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return messages, nil
+}
+
+func (db *SQLiteDB) MarkOutboxSent(ids []uint64) error {
+	query := "update outbox set sent_at = @now where id in (select value from json_each(@ids))"
+
+	_, err := db.ExecContext(db.ctx, query,
+		sql.Named("now", sqlite.Time(time.Now())),
+		sql.Named("ids", sqlite.JSON{V: ids}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox messages as sent: %v", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) DeleteSentOutbox(olderThan time.Duration) (int64, error) {
+	query := "delete from outbox where sent_at < @sent_before"
+
+	res, err := db.ExecContext(db.ctx, query, sql.Named("sent_before", sqlite.Time(time.Now().Add(-olderThan))))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sent outbox messages: %v", err)
+	}
+
+	return res.RowsAffected()
+}
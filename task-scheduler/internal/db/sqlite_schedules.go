@@ -0,0 +1,100 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/schedule"
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+func (db *SQLiteDB) GetDueSchedules() ([]schedule.Schedule, error) {
+	query := "select * from schedules where next_run_at <= @now order by next_run_at"
+
+	rows, err := db.QueryContext(db.ctx, query, sql.Named("now", sqlite.Time(time.Now())))
+	if err != nil {
+		return nil, fmt.Errorf("failed to select due schedules: %v", err)
+	}
+	defer rows.Close()
+
+	schedules := []schedule.Schedule{}
+	for rows.Next() {
+		s := schedule.Schedule{}
+		err := rows.Scan(
+			&s.ID, &s.UserID, &s.CronExpr, &s.Timezone, &s.Type, sqlite.JSON{V: &s.Payload},
+			&s.MaxRetries, sqlite.JSON{V: &s.RetryPolicy}, &s.NextRunAt, &s.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %v", err)
+		}
+		schedules = append(schedules, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to select due schedules: %v", err)
+	}
+
+	return schedules, nil
+}
+
+func (db *SQLiteDB) MaterializeSchedule(s *schedule.Schedule, t *task.Task, nextRunAt time.Time) (*task.Task, error) {
+	tx, err := db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := "update schedules set next_run_at = @next_run_at where id = @schedule_id and next_run_at = @prev_run_at"
+
+	res, err := tx.ExecContext(db.ctx, query,
+		sql.Named("next_run_at", sqlite.Time(nextRunAt)),
+		sql.Named("schedule_id", s.ID),
+		sql.Named("prev_run_at", sqlite.Time(s.NextRunAt)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update next run of schedule: %v", err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to update next run of schedule: %v", err)
+	}
+	if count == 0 {
+		return nil, ErrScheduleAlreadyMaterialized
+	}
+
+	query = `insert into tasks (id, user_id, type, payload, status, max_retries, run_at, retry_policy)
+	values (@id, @user_id, @type, @payload, 'queued', @max_retries, @run_at, @retry_policy)
+	returning *`
+
+	createdTask := &task.Task{}
+	err = tx.QueryRowContext(db.ctx, query,
+		sql.Named("id", t.ID),
+		sql.Named("user_id", t.UserID),
+		sql.Named("type", t.Type),
+		sql.Named("payload", sqlite.JSON{V: t.Payload}),
+		sql.Named("max_retries", t.MaxRetries),
+		sql.Named("run_at", sqlite.NullTime(t.RunAt)),
+		sql.Named("retry_policy", sqlite.JSON{V: t.RetryPolicy}),
+	).Scan(
+		&createdTask.ID, &createdTask.UserID, &createdTask.Type, sqlite.JSON{V: &createdTask.Payload},
+		&createdTask.Status, &createdTask.Retries, &createdTask.MaxRetries,
+		&createdTask.RunAt, &createdTask.CreatedAt, &createdTask.UpdatedAt, sqlite.JSON{V: &createdTask.RetryPolicy},
+		sqlite.JSON{V: &createdTask.Result}, &createdTask.Priority,
+		&createdTask.UniqueKey, &createdTask.UniqueUntil, &createdTask.UniqueActive,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert task: %v", err)
+	}
+
+	query = "insert into outbox (task_id) values (@task_id)"
+	if _, err := tx.ExecContext(db.ctx, query, sql.Named("task_id", createdTask.ID)); err != nil {
+		return nil, fmt.Errorf("failed to insert task into outbox: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return createdTask, nil
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+)
+
+type SQLiteDB struct {
+	*sql.DB
+	ctx context.Context
+}
+
+func NewSQLiteDB(path string) (*SQLiteDB, error) {
+	ctx := context.Background()
+
+	db, err := sqlite.NewSQLiteDB(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDB{db, ctx}, nil
+}
+
+func (db *SQLiteDB) Ping(ctx context.Context) error {
+	return db.PingContext(ctx)
+}
+
+func (db *SQLiteDB) Close() {
+	db.DB.Close()
+}
+
+func (db *SQLiteDB) EnqueuePostponedTasks() (int64, error) {
+	tx, err := db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := sql.Named("now", sqlite.Time(time.Now()))
+
+	query := `insert into outbox (task_id) select id from tasks
+	where status = 'postponed' and @now >= run_at
+	order by priority desc, run_at, id`
+	if _, err := tx.ExecContext(db.ctx, query, now); err != nil {
+		return 0, fmt.Errorf("failed to enqueue postponed tasks: %v", err)
+	}
+
+	query = "update tasks set status = 'queued' where status = 'postponed' and @now >= run_at"
+	res, err := tx.ExecContext(db.ctx, query, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue postponed tasks: %v", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue postponed tasks: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return count, nil
+}
+
+func (db *SQLiteDB) RequeueStaleTasks(olderThan time.Duration) (int64, error) {
+	query := `insert into outbox (task_id) select id from tasks t
+	where status = 'queued' and run_at < @stale_before
+	and not exists (
+		select 1 from outbox o where o.task_id = t.id
+		and (o.sent_at is null or o.created_at >= @stale_before)
+	)
+	order by priority desc, run_at, id`
+
+	res, err := db.ExecContext(db.ctx, query, sql.Named("stale_before", sqlite.Time(time.Now().Add(-olderThan))))
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale tasks: %v", err)
+	}
+
+	return res.RowsAffected()
+}
+
+func (db *SQLiteDB) DeleteExpiredIdempotencyKeys(olderThan time.Duration) (int64, error) {
+	query := "delete from idempotency_keys where created_at < @expires_before"
+
+	res, err := db.ExecContext(db.ctx, query, sql.Named("expires_before", sqlite.Time(time.Now().Add(-olderThan))))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %v", err)
+	}
+
+	return res.RowsAffected()
+}
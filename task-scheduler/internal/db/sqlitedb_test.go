@@ -0,0 +1,181 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+)
+
+func newTestSQLiteDB(t *testing.T) *SQLiteDB {
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "taskflow.db"))
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	_, err = db.Exec("insert into users (id, email, password_hash) values (1, 'test@test.com', 'hash')")
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestSQLiteOutbox(t *testing.T) {
+	db := newTestSQLiteDB(t)
+
+	dueID, futureID := uuid.New(), uuid.New()
+	query := `insert into tasks (id, user_id, type, payload, status, run_at)
+	values (?, 1, 'send_email', '{"to":"test@test.com"}', 'postponed', ?)`
+
+	_, err := db.Exec(query, dueID, sqlite.Time(time.Now().Add(-time.Minute)))
+	require.NoError(t, err)
+	_, err = db.Exec(query, futureID, sqlite.Time(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+
+	count, err := db.EnqueuePostponedTasks()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	messages, err := db.ClaimOutbox(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, dueID, messages[0].Task.ID)
+	assert.Equal(t, "queued", messages[0].Task.Status)
+	assert.Equal(t, map[string]interface{}{"to": "test@test.com"}, messages[0].Task.Payload)
+
+	leased, err := db.ClaimOutbox(10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, leased)
+
+	require.NoError(t, db.MarkOutboxSent([]uint64{messages[0].ID}))
+
+	deleted, err := db.DeleteSentOutbox(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+
+	deleted, err = db.DeleteSentOutbox(-time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestSQLiteRequeueStaleTasks(t *testing.T) {
+	db := newTestSQLiteDB(t)
+
+	staleID, freshID := uuid.New(), uuid.New()
+	query := `insert into tasks (id, user_id, type, payload, status, run_at)
+	values (?, 1, 'send_email', '{"to":"test@test.com"}', 'queued', ?)`
+
+	_, err := db.Exec(query, staleID, sqlite.Time(time.Now().Add(-2*time.Hour)))
+	require.NoError(t, err)
+	_, err = db.Exec(query, freshID, sqlite.Time(time.Now()))
+	require.NoError(t, err)
+
+	count, err := db.RequeueStaleTasks(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = db.RequeueStaleTasks(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	messages, err := db.ClaimOutbox(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, staleID, messages[0].Task.ID)
+	require.NoError(t, db.MarkOutboxSent([]uint64{messages[0].ID}))
+
+	count, err = db.RequeueStaleTasks(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	_, err = db.Exec("update outbox set created_at = ?", sqlite.Time(time.Now().Add(-2*time.Hour)))
+	require.NoError(t, err)
+
+	count, err = db.RequeueStaleTasks(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestSQLiteDeleteExpiredIdempotencyKeys(t *testing.T) {
+	db := newTestSQLiteDB(t)
+
+	query := "insert into idempotency_keys (user_id, key, fingerprint, created_at) values (1, ?, 'fingerprint', ?)"
+	_, err := db.Exec(query, "expired", sqlite.Time(time.Now().Add(-25*time.Hour)))
+	require.NoError(t, err)
+	_, err = db.Exec(query, "fresh", sqlite.Time(time.Now()))
+	require.NoError(t, err)
+
+	count, err := db.DeleteExpiredIdempotencyKeys(24 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	var key string
+	require.NoError(t, db.QueryRow("select key from idempotency_keys").Scan(&key))
+	assert.Equal(t, "fresh", key)
+}
+
+func TestSQLiteSchedules(t *testing.T) {
+	db := newTestSQLiteDB(t)
+
+	nextRunAt := time.Now().Add(-time.Second).UTC().Truncate(time.Millisecond)
+	_, err := db.Exec(`insert into schedules (id, user_id, cron_expr, type, payload, next_run_at)
+	values (?, 1, '* * * * *', 'send_email', '{"to":"test@test.com"}', ?)`, uuid.New(), sqlite.Time(nextRunAt))
+	require.NoError(t, err)
+
+	schedules, err := db.GetDueSchedules()
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.Equal(t, nextRunAt, schedules[0].NextRunAt)
+
+	s := schedules[0]
+	runAt := s.NextRunAt
+	newTask := &task.Task{
+		ID:         uuid.New(),
+		UserID:     s.UserID,
+		Type:       s.Type,
+		Payload:    s.Payload,
+		MaxRetries: s.MaxRetries,
+		RunAt:      &runAt,
+	}
+
+	created, err := db.MaterializeSchedule(&s, newTask, s.NextRunAt.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "queued", created.Status)
+	assert.Equal(t, runAt, *created.RunAt)
+
+	_, err = db.MaterializeSchedule(&s, newTask, s.NextRunAt.Add(time.Minute))
+	assert.Equal(t, ErrScheduleAlreadyMaterialized, err)
+
+	messages, err := db.ClaimOutbox(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, newTask.ID, messages[0].Task.ID)
+}
+
+func TestSQLiteEnqueueByPriority(t *testing.T) {
+	db := newTestSQLiteDB(t)
+
+	lowID, highID := uuid.New(), uuid.New()
+	query := `insert into tasks (id, user_id, type, payload, status, run_at, priority)
+	values (?, 1, 'send_email', '{}', 'postponed', ?, ?)`
+
+	_, err := db.Exec(query, lowID, sqlite.Time(time.Now().Add(-2*time.Minute)), 0)
+	require.NoError(t, err)
+	_, err = db.Exec(query, highID, sqlite.Time(time.Now().Add(-time.Minute)), 9)
+	require.NoError(t, err)
+
+	count, err := db.EnqueuePostponedTasks()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	messages, err := db.ClaimOutbox(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, highID, messages[0].Task.ID)
+	assert.Equal(t, uint8(9), messages[0].Task.Priority)
+	assert.Equal(t, lowID, messages[1].Task.ID)
+}
@@ -1,11 +1,20 @@
 package scheduler
 
 import (
-	"github.com/google/uuid"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/schedule"
 	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/imightbuyaboat/TaskFlow/task-scheduler/internal/db"
 )
 
 type DB interface {
-	GetPostponedTasks() ([]task.Task, error)
-	UpdateStatusOfTask(taskID uuid.UUID, status string) error
+	EnqueuePostponedTasks() (int64, error)
+	RequeueStaleTasks(olderThan time.Duration) (int64, error)
+	DeleteExpiredIdempotencyKeys(olderThan time.Duration) (int64, error)
+	GetDueSchedules() ([]schedule.Schedule, error)
+	MaterializeSchedule(s *schedule.Schedule, t *task.Task, nextRunAt time.Time) (*task.Task, error)
+	ClaimOutbox(limit int, lease time.Duration) ([]db.OutboxMessage, error)
+	MarkOutboxSent(ids []uint64) error
+	DeleteSentOutbox(olderThan time.Duration) (int64, error)
 }
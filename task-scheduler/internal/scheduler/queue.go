@@ -5,5 +5,5 @@ import (
 )
 
 type Queue interface {
-	Publish(t *task.Task) error
+	PublishBatch(tasks []*task.Task) []error
 }
@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"go.uber.org/zap"
+)
+
+const (
+	outboxLease     = 30 * time.Second
+	outboxRetention = 24 * time.Hour
+)
+
+type Relay struct {
+	interval  time.Duration
+	batchSize int
+	db        DB
+	queue     Queue
+	logger    *zap.Logger
+}
+
+func NewRelay(interval time.Duration, batchSize int, db DB, queue Queue, logger *zap.Logger) (*Relay, error) {
+	return &Relay{
+		interval:  interval,
+		batchSize: batchSize,
+		db:        db,
+		queue:     queue,
+		logger:    logger,
+	}, nil
+}
+
+func (r *Relay) EnterLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("relay stopped")
+			return
+		case <-time.After(r.interval):
+		}
+
+		start := time.Now()
+		for ctx.Err() == nil && r.relayMessages() == r.batchSize {
+		}
+		r.cleanupMessages()
+		metrics.SchedulerLoopDuration.WithLabelValues("relay").Observe(time.Since(start).Seconds())
+	}
+}
+
+func (r *Relay) relayMessages() int {
+	messages, err := r.db.ClaimOutbox(r.batchSize, outboxLease)
+	if err != nil {
+		r.logger.Error("failed to claim outbox messages", zap.Error(err))
+		return 0
+	}
+	metrics.SchedulerBatchSize.WithLabelValues("relay").Observe(float64(len(messages)))
+	if len(messages) == 0 {
+		return 0
+	}
+
+	tasks := make([]*task.Task, len(messages))
+	for i := range messages {
+		tasks[i] = &messages[i].Task
+	}
+
+	errs := r.queue.PublishBatch(tasks)
+
+	sent := make([]uint64, 0, len(messages))
+	for i, m := range messages {
+		if errs[i] != nil {
+			r.logger.Error("failed to publish task", zap.Error(errs[i]), zap.String("task_id", m.Task.ID.String()))
+			continue
+		}
+		metrics.TasksPublished.WithLabelValues(m.Task.Type).Inc()
+		sent = append(sent, m.ID)
+	}
+
+	if len(sent) > 0 {
+		if err := r.db.MarkOutboxSent(sent); err != nil {
+			r.logger.Error("failed to mark outbox messages as sent", zap.Error(err))
+			return 0
+		}
+	}
+
+	r.logger.Info("successfully relay outbox messages", zap.Int("claimed", len(messages)), zap.Int("sent", len(sent)))
+	if len(sent) < len(messages) {
+		return 0
+	}
+	return len(messages)
+}
+
+func (r *Relay) cleanupMessages() {
+	count, err := r.db.DeleteSentOutbox(outboxRetention)
+	if err != nil {
+		r.logger.Error("failed to delete sent outbox messages", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		r.logger.Info("successfully delete sent outbox messages", zap.Int64("count", count))
+	}
+}
@@ -1,53 +1,148 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
+	"github.com/imightbuyaboat/TaskFlow/pkg/schedule"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/imightbuyaboat/TaskFlow/task-scheduler/internal/db"
 	"go.uber.org/zap"
 )
 
+const (
+	staleTaskTimeout        = time.Hour
+	idempotencyKeyRetention = 24 * time.Hour
+)
+
 type Scheduler struct {
 	interval time.Duration
 	db       DB
-	queue    Queue
 	logger   *zap.Logger
 }
 
-func NewScheduler(interval time.Duration, db DB, queue Queue, logger *zap.Logger) (*Scheduler, error) {
+func NewScheduler(interval time.Duration, db DB, logger *zap.Logger) (*Scheduler, error) {
 	return &Scheduler{
 		interval: interval,
 		db:       db,
-		queue:    queue,
 		logger:   logger,
 	}, nil
 }
 
-func (s *Scheduler) EnterLoop() {
+func (s *Scheduler) EnterLoop(ctx context.Context) {
 	for {
-		time.Sleep(s.interval)
+		select {
+		case <-ctx.Done():
+			s.logger.Info("scheduler stopped")
+			return
+		case <-time.After(s.interval):
+		}
 
+		start := time.Now()
+		s.processSchedules()
 		s.processTasks()
+		s.cleanupIdempotencyKeys()
+		metrics.SchedulerLoopDuration.WithLabelValues("scheduler").Observe(time.Since(start).Seconds())
 	}
 }
 
 func (s *Scheduler) processTasks() {
-	tasks, err := s.db.GetPostponedTasks()
+	count, err := s.db.EnqueuePostponedTasks()
+	if err != nil {
+		s.logger.Error("failed to enqueue postponed tasks", zap.Error(err))
+		return
+	}
+	metrics.SchedulerBatchSize.WithLabelValues("postponed_tasks").Observe(float64(count))
+	s.logger.Info("successfully enqueue postponed tasks", zap.Int64("count", count))
+
+	count, err = s.db.RequeueStaleTasks(staleTaskTimeout)
+	if err != nil {
+		s.logger.Error("failed to requeue stale tasks", zap.Error(err))
+		return
+	}
+	metrics.SchedulerBatchSize.WithLabelValues("stale_tasks").Observe(float64(count))
+	if count > 0 {
+		s.logger.Info("successfully requeue stale tasks", zap.Int64("count", count))
+	}
+}
+
+func (s *Scheduler) cleanupIdempotencyKeys() {
+	count, err := s.db.DeleteExpiredIdempotencyKeys(idempotencyKeyRetention)
+	if err != nil {
+		s.logger.Error("failed to delete expired idempotency keys", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		s.logger.Info("successfully delete expired idempotency keys", zap.Int64("count", count))
+	}
+}
+
+func (s *Scheduler) processSchedules() {
+	schedules, err := s.db.GetDueSchedules()
 	if err != nil {
-		s.logger.Error("failed to select postponed tasks", zap.Error(err))
+		s.logger.Error("failed to select due schedules", zap.Error(err))
 		return
 	}
-	s.logger.Info("successfully select postponed tasks", zap.Int("count", len(tasks)))
+	metrics.SchedulerBatchSize.WithLabelValues("schedules").Observe(float64(len(schedules)))
+	s.logger.Info("successfully select due schedules", zap.Int("count", len(schedules)))
 
-	for _, t := range tasks {
-		if err := s.db.UpdateStatusOfTask(t.ID, "queued"); err != nil {
-			s.logger.Error("failed to update status of task", zap.Error(err), zap.String("task_id", t.ID.String()))
+	for _, sch := range schedules {
+		nextRunAt, err := schedule.NextRun(sch.CronExpr, sch.Timezone, time.Now())
+		if err != nil {
+			s.logger.Error("failed to calculate next run of schedule", zap.Error(err), zap.String("schedule_id", sch.ID.String()))
 			continue
 		}
 
-		if err := s.queue.Publish(&t); err != nil {
-			s.logger.Error("failed to publish task", zap.Error(err), zap.String("task_id", t.ID.String()))
+		t, err := s.newScheduledTask(&sch)
+		if err != nil {
+			s.logger.Error("failed to build task from schedule", zap.Error(err), zap.String("schedule_id", sch.ID.String()))
+			continue
 		}
 
-		s.logger.Info("successfully publish task", zap.String("task_id", t.ID.String()))
+		createdTask, err := s.db.MaterializeSchedule(&sch, t, nextRunAt)
+		if err != nil {
+			if errors.Is(err, db.ErrScheduleAlreadyMaterialized) {
+				s.logger.Info("schedule already materialized", zap.String("schedule_id", sch.ID.String()))
+				continue
+			}
+			s.logger.Error("failed to materialize schedule", zap.Error(err), zap.String("schedule_id", sch.ID.String()))
+			continue
+		}
+
+		metrics.TasksCreated.WithLabelValues(createdTask.Type).Inc()
+		s.logger.Info("successfully materialize schedule", zap.String("schedule_id", sch.ID.String()), zap.String("task_id", createdTask.ID.String()))
 	}
 }
+
+func (s *Scheduler) newScheduledTask(sch *schedule.Schedule) (*task.Task, error) {
+	payload, err := schedule.RenderPayload(sch.Payload, schedule.TemplateData{
+		ScheduleID:  sch.ID,
+		ScheduledAt: sch.NextRunAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := task.ValidatePayload(sch.Type, payload); err != nil {
+		return nil, err
+	}
+
+	taskID, err := uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	runAt := sch.NextRunAt.UTC()
+	return &task.Task{
+		ID:          taskID,
+		UserID:      sch.UserID,
+		Type:        sch.Type,
+		Payload:     payload,
+		MaxRetries:  sch.MaxRetries,
+		RunAt:       &runAt,
+		RetryPolicy: sch.RetryPolicy,
+	}, nil
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/health"
+	"github.com/imightbuyaboat/TaskFlow/pkg/queue"
+	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/email"
+	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/file_downloading"
+	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/image_processing"
+	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/worker"
+	"go.uber.org/zap"
+)
+
+const shutdownTimeout = 30 * time.Second
+
+type Config struct {
+	DatabaseURL  string
+	Pools        map[string]int
+	OptionalMail bool
+	HealthPrefix string
+}
+
+type App struct {
+	db      database
+	workers []*worker.Worker
+	logger  *zap.Logger
+}
+
+func New(cfg Config, q queue.Queue, checker *health.Checker, logger *zap.Logger) (*App, error) {
+	if len(cfg.Pools) == 0 {
+		return nil, fmt.Errorf("at least one worker pool should be configured")
+	}
+
+	executers, err := newExecuters(cfg.Pools, cfg.OptionalMail, logger)
+	if err != nil {
+		return nil, err
+	}
+	if len(executers) == 0 {
+		return nil, fmt.Errorf("no executers are available for configured worker pools")
+	}
+
+	db, err := openDB(cfg.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to db: %v", err)
+	}
+
+	checker.AddReadiness(cfg.HealthPrefix+"db", db.Ping)
+
+	var workers []*worker.Worker
+	for _, taskType := range slices.Sorted(maps.Keys(executers)) {
+		for i := 0; i < cfg.Pools[taskType]; i++ {
+			id := len(workers) + 1
+
+			w, err := worker.NewWorker(id, taskType, q, executers[taskType], db, logger)
+			if err != nil {
+				db.Close()
+				return nil, fmt.Errorf("failed to create worker: %v", err)
+			}
+
+			checker.AddLiveness(fmt.Sprintf("worker-%d", id), w.Check)
+			workers = append(workers, w)
+		}
+
+		logger.Info("started worker pool", zap.String("type", taskType), zap.Int("workers", cfg.Pools[taskType]))
+	}
+
+	return &App{
+		db:      db,
+		workers: workers,
+		logger:  logger,
+	}, nil
+}
+
+func newExecuters(pools map[string]int, optionalMail bool, logger *zap.Logger) (map[string]worker.Executer, error) {
+	executers := make(map[string]worker.Executer, len(pools))
+	for taskType, count := range pools {
+		if count <= 0 {
+			return nil, fmt.Errorf("number of %s workers should be positive", taskType)
+		}
+
+		switch taskType {
+		case "process_image":
+			executers[taskType] = image_processing.NewImageProcessor()
+		case "download_files":
+			executers[taskType] = file_downloading.NewFileDownloader()
+		case "send_email":
+			mailDialer, err := email.NewMailDialer()
+			if err != nil {
+				if !optionalMail {
+					return nil, fmt.Errorf("failed to create mail dialer: %v", err)
+				}
+				logger.Warn("mail dialer is not configured, send_email tasks are disabled", zap.Error(err))
+				continue
+			}
+			executers[taskType] = mailDialer
+		default:
+			return nil, fmt.Errorf("unknown type of task %q", taskType)
+		}
+	}
+
+	return executers, nil
+}
+
+func (a *App) Run(ctx context.Context) {
+	defer a.db.Close()
+
+	execCtx, cancelExec := context.WithCancel(context.Background())
+	defer cancelExec()
+
+	var wg sync.WaitGroup
+	for _, w := range a.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Work(ctx, execCtx)
+		}()
+	}
+
+	<-ctx.Done()
+	a.logger.Info("shutting down workers", zap.Duration("timeout", shutdownTimeout))
+
+	timer := time.AfterFunc(shutdownTimeout, func() {
+		a.logger.Info("shutdown timeout exceeded, interrupting running tasks")
+		cancelExec()
+	})
+	wg.Wait()
+	timer.Stop()
+}
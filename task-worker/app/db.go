@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/db"
+	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/worker"
+)
+
+type database interface {
+	worker.DB
+	Ping(ctx context.Context) error
+	Close()
+}
+
+func openDB(databaseURL string) (database, error) {
+	if path, ok := sqlite.PathFromDSN(databaseURL); ok {
+		db, err := db.NewSQLiteDB(path)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+
+	db, err := db.NewPostgresDB(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
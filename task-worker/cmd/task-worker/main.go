@@ -1,18 +1,19 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 
+	"github.com/imightbuyaboat/TaskFlow/pkg/config"
+	"github.com/imightbuyaboat/TaskFlow/pkg/health"
 	"github.com/imightbuyaboat/TaskFlow/pkg/logger"
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
 	"github.com/imightbuyaboat/TaskFlow/pkg/queue"
-	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/db"
-	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/email"
-	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/file_downloading"
-	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/image_processing"
-	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/worker"
-	"github.com/joho/godotenv"
+	"github.com/imightbuyaboat/TaskFlow/task-worker/app"
 	"go.uber.org/zap"
 )
 
@@ -20,55 +21,57 @@ func main() {
 	logger.InitLogger()
 	log := logger.GetLogger()
 
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("failed to load .env file", zap.Error(err))
+	cfg := config.Default()
+	if _, err := cfg.Load(os.Args[0], os.Args[1:]); err != nil {
+		log.Fatal("failed to load config", zap.Error(err))
 	}
 
-	postgresURL := fmt.Sprintf("postgres://%s:%s@db:%s/%s",
-		os.Getenv("POSTGRES_USER"), os.Getenv("POSTGRES_PASSWORD"),
-		os.Getenv("POSTGRES_PORT"), os.Getenv("POSTGRES_DB"))
+	if err := logger.SetLevel(cfg.Log.Level); err != nil {
+		log.Fatal("failed to set log level", zap.Error(err))
+	}
+
+	log.Info("loaded config", zap.Any("config", cfg.Redacted()))
 
-	db, err := db.NewPostgresDB(postgresURL)
+	queue, err := queue.NewQueue(cfg.Queue.Backend, cfg.AMQP.URL(), cfg.DatabaseURL(), log)
 	if err != nil {
-		log.Fatal("failed to create PostgresDB connection", zap.Error(err))
+		log.Fatal("failed to connect to queue", zap.Error(err))
 	}
 
-	amqpURL := fmt.Sprintf("amqp://%s:%s@rabbitmq:%s/",
-		os.Getenv("AMQP_USER"), os.Getenv("AMQP_PASSWORD"),
-		os.Getenv("AMQP_PORT"))
+	checker := health.NewChecker()
+	checker.AddReadiness("queue", queue.Check)
 
-	queue, err := queue.NewRabbitMQQueue(amqpURL)
+	pools, err := cfg.Worker.Pools()
 	if err != nil {
-		log.Fatal("failed to create RabbitMQ connection", zap.Error(err))
+		log.Fatal("invalid worker types", zap.Error(err))
 	}
 
-	mailDialer, err := email.NewMailDialer()
+	a, err := app.New(app.Config{
+		DatabaseURL: cfg.DatabaseURL(),
+		Pools:       pools,
+	}, queue, checker, log)
 	if err != nil {
-		log.Fatal("failed to create mail dialer", zap.Error(err))
+		log.Fatal("failed to create workers", zap.Error(err))
 	}
 
-	imageProcessor := image_processing.NewImageProcessor()
-	fileDonwloader := file_downloading.NewFileDownloader()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", checker.LivenessHandler())
+	mux.Handle("/readyz", checker.ReadinessHandler())
 
-	executers := map[string]worker.Executer{
-		"send_email":     mailDialer,
-		"process_image":  imageProcessor,
-		"download_files": fileDonwloader,
-	}
+	go func() {
+		if err := http.ListenAndServe(":"+strconv.Itoa(cfg.Metrics.Port), mux); err != nil {
+			log.Fatal("failed to start probes server", zap.Error(err))
+		}
+	}()
 
-	numOfWorkersStr := os.Getenv("NUMOFWORKERS")
-	numOfWorkers, err := strconv.Atoi(numOfWorkersStr)
-	if err != nil {
-		log.Fatal("incorrect NUMOFWORKERS format", zap.Error(err))
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	for i := 0; i < numOfWorkers; i++ {
-		w, err := worker.NewWorker(i+1, queue, executers, db, log)
-		if err != nil {
-			log.Fatal("failed to create worker", zap.Error(err))
-		}
-		go w.Work()
+	a.Run(ctx)
+
+	if err := queue.Close(); err != nil {
+		log.Error("failed to close queue", zap.Error(err))
 	}
 
-	select {}
+	log.Info("workers gracefully stopped")
 }
@@ -4,4 +4,7 @@ import "errors"
 
 var (
 	ErrMaxRetriesReached = errors.New("error reached max retries")
+	ErrTaskCancelled     = errors.New("task was cancelled")
+	ErrTaskFinished      = errors.New("task is already finished")
+	ErrTaskNotQueued     = errors.New("task is not queued")
 )
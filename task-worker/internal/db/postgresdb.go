@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -27,36 +28,242 @@ func NewPostgresDB(postgresURL string) (*PostgresDB, error) {
 	return &PostgresDB{pool, ctx}, nil
 }
 
-func (db *PostgresDB) UpdateStatusOfTask(taskID uuid.UUID, status string) error {
-	query := "update tasks set status = @status"
-	if status == "processing" {
-		query += ", retries = retries + 1"
+func (db *PostgresDB) StartTask(taskID uuid.UUID, redelivered bool) (uint8, error) {
+	query := `update tasks set status = 'processing', retries = retries + 1
+	where id = @task_id and (status = 'queued' or (@redelivered and status = 'processing'))
+	and retries < max_retries
+	returning retries`
+	args := pgx.NamedArgs{
+		"task_id":     taskID,
+		"redelivered": redelivered,
 	}
-	query += " where id = @task_id"
 
-	if status == "processing" {
-		query += " and retries < max_retries returning id"
+	var retries uint8
+	if err := db.QueryRow(db.ctx, query, args).Scan(&retries); err != nil {
+		if err == pgx.ErrNoRows {
+			currentStatus, err := db.GetStatusOfTask(taskID)
+			if err != nil {
+				return 0, err
+			}
+			switch currentStatus {
+			case "cancelled":
+				return 0, ErrTaskCancelled
+			case "done", "failed", "skipped":
+				return 0, ErrTaskFinished
+			case "queued":
+				return 0, ErrMaxRetriesReached
+			case "processing":
+				if redelivered {
+					return 0, ErrMaxRetriesReached
+				}
+			}
+			return 0, ErrTaskNotQueued
+		}
+		return 0, fmt.Errorf("failed to update task status: %v", err)
 	}
 
+	return retries, nil
+}
+
+func (db *PostgresDB) CompleteTask(taskID uuid.UUID, result map[string]interface{}) error {
+	tx, err := db.Begin(db.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(db.ctx)
+
+	if err := db.updateStatusOfTask(tx, taskID, "done"); err != nil {
+		return err
+	}
+
+	query := "update tasks set result = @result where id = @task_id and status = 'done'"
+	args := pgx.NamedArgs{
+		"result":  result,
+		"task_id": taskID,
+	}
+
+	if _, err := tx.Exec(db.ctx, query, args); err != nil {
+		return fmt.Errorf("failed to save result of task: %v", err)
+	}
+
+	query = `select t.id from tasks t join task_dependencies d on d.task_id = t.id
+	where d.depends_on = @task_id order by t.id for update of t`
+
+	if _, err := tx.Exec(db.ctx, query, args); err != nil {
+		return fmt.Errorf("failed to lock dependents of task: %v", err)
+	}
+
+	query = `update tasks set status = 'postponed'
+	where status = 'waiting'
+	and id in (select task_id from task_dependencies where depends_on = @task_id)
+	and not exists (
+		select 1 from task_dependencies d join tasks p on p.id = d.depends_on
+		where d.task_id = tasks.id and p.status <> 'done'
+	)`
+
+	if _, err := tx.Exec(db.ctx, query, args); err != nil {
+		return fmt.Errorf("failed to release dependents of task: %v", err)
+	}
+
+	if err := tx.Commit(db.ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (db *PostgresDB) FailTask(taskID uuid.UUID) error {
+	tx, err := db.Begin(db.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(db.ctx)
+
+	if err := db.updateStatusOfTask(tx, taskID, "failed"); err != nil {
+		return err
+	}
+
+	query := `with recursive dependents as (
+		select task_id from task_dependencies where depends_on = @task_id
+		union
+		select d.task_id from task_dependencies d join dependents p on d.depends_on = p.task_id
+	)
+	update tasks set status = 'skipped'
+	where id in (select task_id from dependents) and status = 'waiting'`
+	args := pgx.NamedArgs{
+		"task_id": taskID,
+	}
+
+	if _, err := tx.Exec(db.ctx, query, args); err != nil {
+		return fmt.Errorf("failed to skip dependents of task: %v", err)
+	}
+
+	if err := tx.Commit(db.ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (db *PostgresDB) updateStatusOfTask(tx pgx.Tx, taskID uuid.UUID, status string) error {
+	query := "update tasks set status = @status where id = @task_id and status not in ('cancelled', 'done')"
 	args := pgx.NamedArgs{
 		"status":  status,
 		"task_id": taskID,
 	}
 
-	if status == "processing" {
-		var id uuid.UUID
-		if err := db.QueryRow(db.ctx, query, args).Scan(&id); err != nil {
-			if err == pgx.ErrNoRows {
-				return ErrMaxRetriesReached
-			}
-			return fmt.Errorf("failed to update task status: %v", err)
+	_, err := tx.Exec(db.ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("failed to update task status: %v", err)
+	}
+
+	return nil
+}
+
+func (db *PostgresDB) PostponeTask(taskID uuid.UUID, delay time.Duration) error {
+	query := `update tasks set status = 'postponed',
+	run_at = now() + @delay_ms * interval '1 millisecond'
+	where id = @task_id and status <> 'cancelled'`
+	args := pgx.NamedArgs{
+		"delay_ms": delay.Milliseconds(),
+		"task_id":  taskID,
+	}
+
+	_, err := db.Exec(db.ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("failed to postpone task: %v", err)
+	}
+
+	return nil
+}
+
+func (db *PostgresDB) ReleaseTask(taskID uuid.UUID) error {
+	query := `update tasks set status = 'queued', retries = greatest(retries - 1, 0)
+	where id = @task_id and status = 'processing'`
+	args := pgx.NamedArgs{
+		"task_id": taskID,
+	}
+
+	_, err := db.Exec(db.ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("failed to release task: %v", err)
+	}
+
+	return nil
+}
+
+func (db *PostgresDB) GetStatusOfTask(taskID uuid.UUID) (string, error) {
+	query := "select status from tasks where id = @task_id"
+	args := pgx.NamedArgs{
+		"task_id": taskID,
+	}
+
+	var status string
+	if err := db.QueryRow(db.ctx, query, args).Scan(&status); err != nil {
+		return "", fmt.Errorf("failed to select task status: %v", err)
+	}
+
+	return status, nil
+}
+
+func (db *PostgresDB) GetResultsOfTasks(taskIDs []uuid.UUID) (map[uuid.UUID]map[string]interface{}, error) {
+	query := "select id, result from tasks where id = any(@task_ids)"
+	args := pgx.NamedArgs{
+		"task_ids": taskIDs,
+	}
+
+	rows, err := db.Query(db.ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select results of tasks: %v", err)
+	}
+	defer rows.Close()
+
+	results := make(map[uuid.UUID]map[string]interface{}, len(taskIDs))
+	for rows.Next() {
+		var taskID uuid.UUID
+		var result map[string]interface{}
+		if err := rows.Scan(&taskID, &result); err != nil {
+			return nil, fmt.Errorf("failed to scan result of task: %v", err)
 		}
-		return nil
+		results[taskID] = result
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to select results of tasks: %v", err)
+	}
+
+	return results, nil
+}
+
+func (db *PostgresDB) StartAttempt(taskID uuid.UUID, workerID string) (uint64, error) {
+	query := `insert into task_attempts (task_id, attempt, worker_id)
+	select id, retries, @worker_id from tasks where id = @task_id
+	returning id`
+	args := pgx.NamedArgs{
+		"task_id":   taskID,
+		"worker_id": workerID,
+	}
+
+	var attemptID uint64
+	if err := db.QueryRow(db.ctx, query, args).Scan(&attemptID); err != nil {
+		return 0, fmt.Errorf("failed to insert attempt: %v", err)
+	}
+
+	return attemptID, nil
+}
+
+func (db *PostgresDB) FinishAttempt(attemptID uint64, errorMessage *string) error {
+	query := `update task_attempts set finished_at = now(),
+	duration_ms = (extract(epoch from (now() - started_at)) * 1000)::bigint,
+	error = @error
+	where id = @attempt_id`
+	args := pgx.NamedArgs{
+		"attempt_id": attemptID,
+		"error":      errorMessage,
 	}
 
 	_, err := db.Exec(db.ctx, query, args)
 	if err != nil {
-		return fmt.Errorf("failed to update task status: %v", err)
+		return fmt.Errorf("failed to update attempt: %v", err)
 	}
 
 	return nil
@@ -0,0 +1,254 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/sqlite"
+)
+
+type SQLiteDB struct {
+	*sql.DB
+	ctx context.Context
+}
+
+func NewSQLiteDB(path string) (*SQLiteDB, error) {
+	ctx := context.Background()
+
+	db, err := sqlite.NewSQLiteDB(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDB{db, ctx}, nil
+}
+
+func (db *SQLiteDB) Ping(ctx context.Context) error {
+	return db.PingContext(ctx)
+}
+
+func (db *SQLiteDB) Close() {
+	db.DB.Close()
+}
+
+func (db *SQLiteDB) StartTask(taskID uuid.UUID, redelivered bool) (uint8, error) {
+	query := `update tasks set status = 'processing', retries = retries + 1
+	where id = @task_id and (status = 'queued' or (@redelivered and status = 'processing'))
+	and retries < max_retries
+	returning retries`
+
+	var retries uint8
+	err := db.QueryRowContext(db.ctx, query,
+		sql.Named("task_id", taskID),
+		sql.Named("redelivered", redelivered),
+	).Scan(&retries)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			currentStatus, err := db.GetStatusOfTask(taskID)
+			if err != nil {
+				return 0, err
+			}
+			switch currentStatus {
+			case "cancelled":
+				return 0, ErrTaskCancelled
+			case "done", "failed", "skipped":
+				return 0, ErrTaskFinished
+			case "queued":
+				return 0, ErrMaxRetriesReached
+			case "processing":
+				if redelivered {
+					return 0, ErrMaxRetriesReached
+				}
+			}
+			return 0, ErrTaskNotQueued
+		}
+		return 0, fmt.Errorf("failed to update task status: %v", err)
+	}
+
+	return retries, nil
+}
+
+func (db *SQLiteDB) CompleteTask(taskID uuid.UUID, result map[string]interface{}) error {
+	tx, err := db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := db.updateStatusOfTask(tx, taskID, "done"); err != nil {
+		return err
+	}
+
+	query := "update tasks set result = @result where id = @task_id and status = 'done'"
+	_, err = tx.ExecContext(db.ctx, query,
+		sql.Named("result", sqlite.JSON{V: result}),
+		sql.Named("task_id", taskID),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save result of task: %v", err)
+	}
+
+	query = `update tasks set status = 'postponed'
+	where status = 'waiting'
+	and id in (select task_id from task_dependencies where depends_on = @task_id)
+	and not exists (
+		select 1 from task_dependencies d join tasks p on p.id = d.depends_on
+		where d.task_id = tasks.id and p.status <> 'done'
+	)`
+
+	if _, err := tx.ExecContext(db.ctx, query, sql.Named("task_id", taskID)); err != nil {
+		return fmt.Errorf("failed to release dependents of task: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) FailTask(taskID uuid.UUID) error {
+	tx, err := db.BeginTx(db.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := db.updateStatusOfTask(tx, taskID, "failed"); err != nil {
+		return err
+	}
+
+	query := `with recursive dependents as (
+		select task_id from task_dependencies where depends_on = @task_id
+		union
+		select d.task_id from task_dependencies d join dependents p on d.depends_on = p.task_id
+	)
+	update tasks set status = 'skipped'
+	where id in (select task_id from dependents) and status = 'waiting'`
+
+	if _, err := tx.ExecContext(db.ctx, query, sql.Named("task_id", taskID)); err != nil {
+		return fmt.Errorf("failed to skip dependents of task: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) updateStatusOfTask(tx *sql.Tx, taskID uuid.UUID, status string) error {
+	query := "update tasks set status = @status where id = @task_id and status not in ('cancelled', 'done')"
+
+	_, err := tx.ExecContext(db.ctx, query,
+		sql.Named("status", status),
+		sql.Named("task_id", taskID),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update task status: %v", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) PostponeTask(taskID uuid.UUID, delay time.Duration) error {
+	query := `update tasks set status = 'postponed', run_at = @run_at
+	where id = @task_id and status <> 'cancelled'`
+
+	_, err := db.ExecContext(db.ctx, query,
+		sql.Named("run_at", sqlite.Time(time.Now().Add(delay))),
+		sql.Named("task_id", taskID),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to postpone task: %v", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) ReleaseTask(taskID uuid.UUID) error {
+	query := `update tasks set status = 'queued', retries = max(retries - 1, 0)
+	where id = @task_id and status = 'processing'`
+
+	_, err := db.ExecContext(db.ctx, query, sql.Named("task_id", taskID))
+	if err != nil {
+		return fmt.Errorf("failed to release task: %v", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) GetStatusOfTask(taskID uuid.UUID) (string, error) {
+	query := "select status from tasks where id = @task_id"
+
+	var status string
+	if err := db.QueryRowContext(db.ctx, query, sql.Named("task_id", taskID)).Scan(&status); err != nil {
+		return "", fmt.Errorf("failed to select task status: %v", err)
+	}
+
+	return status, nil
+}
+
+func (db *SQLiteDB) GetResultsOfTasks(taskIDs []uuid.UUID) (map[uuid.UUID]map[string]interface{}, error) {
+	query := "select id, result from tasks where id in (select value from json_each(@task_ids))"
+
+	rows, err := db.QueryContext(db.ctx, query, sql.Named("task_ids", sqlite.JSON{V: taskIDs}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to select results of tasks: %v", err)
+	}
+	defer rows.Close()
+
+	results := make(map[uuid.UUID]map[string]interface{}, len(taskIDs))
+	for rows.Next() {
+		var taskID uuid.UUID
+		var result map[string]interface{}
+		if err := rows.Scan(&taskID, sqlite.JSON{V: &result}); err != nil {
+			return nil, fmt.Errorf("failed to scan result of task: %v", err)
+		}
+		results[taskID] = result
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to select results of tasks: %v", err)
+	}
+
+	return results, nil
+}
+
+func (db *SQLiteDB) StartAttempt(taskID uuid.UUID, workerID string) (uint64, error) {
+	query := `insert into task_attempts (task_id, attempt, worker_id)
+	select id, retries, @worker_id from tasks where id = @task_id
+	returning id`
+
+	var attemptID uint64
+	err := db.QueryRowContext(db.ctx, query,
+		sql.Named("task_id", taskID),
+		sql.Named("worker_id", workerID),
+	).Scan(&attemptID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert attempt: %v", err)
+	}
+
+	return attemptID, nil
+}
+
+func (db *SQLiteDB) FinishAttempt(attemptID uint64, errorMessage *string) error {
+	query := `update task_attempts set finished_at = @now,
+	duration_ms = cast(round((julianday(@now) - julianday(started_at)) * 86400000) as integer),
+	error = @error
+	where id = @attempt_id`
+
+	_, err := db.ExecContext(db.ctx, query,
+		sql.Named("now", sqlite.Time(time.Now())),
+		sql.Named("attempt_id", attemptID),
+		sql.Named("error", errorMessage),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update attempt: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSQLiteDB(t *testing.T) *SQLiteDB {
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "taskflow.db"))
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	_, err = db.Exec("insert into users (id, email, password_hash) values (1, 'test@test.com', 'hash')")
+	require.NoError(t, err)
+
+	return db
+}
+
+func insertTestTask(t *testing.T, db *SQLiteDB, status string, maxRetries uint8, dependsOn ...uuid.UUID) uuid.UUID {
+	id := uuid.New()
+	_, err := db.Exec(`insert into tasks (id, user_id, type, payload, status, max_retries)
+	values (?, 1, 'send_email', '{}', ?, ?)`, id, status, maxRetries)
+	require.NoError(t, err)
+
+	for _, parentID := range dependsOn {
+		_, err = db.Exec("insert into task_dependencies (task_id, depends_on) values (?, ?)", id, parentID)
+		require.NoError(t, err)
+	}
+
+	return id
+}
+
+func TestSQLiteStartTask(t *testing.T) {
+	db := newTestSQLiteDB(t)
+
+	tests := []struct {
+		name        string
+		status      string
+		maxRetries  uint8
+		redelivered bool
+		wantRetries uint8
+		wantErr     error
+	}{
+		{"queued", "queued", 3, false, 1, nil},
+		{"cancelled", "cancelled", 3, false, 0, ErrTaskCancelled},
+		{"finished", "done", 3, false, 0, ErrTaskFinished},
+		{"max retries reached", "queued", 0, false, 0, ErrMaxRetriesReached},
+		{"postponed", "postponed", 3, false, 0, ErrTaskNotQueued},
+		{"processing by another worker", "processing", 3, false, 0, ErrTaskNotQueued},
+		{"redelivered processing", "processing", 3, true, 1, nil},
+		{"redelivered with max retries reached", "processing", 0, true, 0, ErrMaxRetriesReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := insertTestTask(t, db, tt.status, tt.maxRetries)
+
+			retries, err := db.StartTask(id, tt.redelivered)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantRetries, retries)
+		})
+	}
+}
+
+func TestSQLiteTaskLifecycle(t *testing.T) {
+	db := newTestSQLiteDB(t)
+
+	parent := insertTestTask(t, db, "queued", 3)
+	child := insertTestTask(t, db, "waiting", 3, parent)
+	grandchild := insertTestTask(t, db, "waiting", 3, child)
+
+	_, err := db.StartTask(parent, false)
+	require.NoError(t, err)
+
+	attemptID, err := db.StartAttempt(parent, "worker-1")
+	require.NoError(t, err)
+
+	require.NoError(t, db.ReleaseTask(parent))
+
+	status, err := db.GetStatusOfTask(parent)
+	require.NoError(t, err)
+	assert.Equal(t, "queued", status)
+
+	require.NoError(t, db.PostponeTask(parent, time.Minute))
+
+	status, err = db.GetStatusOfTask(parent)
+	require.NoError(t, err)
+	assert.Equal(t, "postponed", status)
+
+	errorMessage := "timeout"
+	require.NoError(t, db.FinishAttempt(attemptID, &errorMessage))
+
+	var gotError string
+	var durationMs int64
+	err = db.QueryRow("select error, duration_ms from task_attempts where id = ?", attemptID).Scan(&gotError, &durationMs)
+	require.NoError(t, err)
+	assert.Equal(t, errorMessage, gotError)
+	assert.GreaterOrEqual(t, durationMs, int64(0))
+
+	require.NoError(t, db.CompleteTask(parent, map[string]interface{}{"path": "image_processed.png"}))
+
+	status, err = db.GetStatusOfTask(child)
+	require.NoError(t, err)
+	assert.Equal(t, "postponed", status)
+
+	results, err := db.GetResultsOfTasks([]uuid.UUID{parent, child})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]map[string]interface{}{
+		parent: {"path": "image_processed.png"},
+		child:  nil,
+	}, results)
+
+	require.NoError(t, db.FailTask(child))
+
+	status, err = db.GetStatusOfTask(child)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", status)
+
+	status, err = db.GetStatusOfTask(grandchild)
+	require.NoError(t, err)
+	assert.Equal(t, "skipped", status)
+}
@@ -1,19 +1,30 @@
 package email
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/mail"
+	"net/smtp"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/imightbuyaboat/TaskFlow/pkg/task"
 	"gopkg.in/gomail.v2"
 )
 
+const dialTimeout = 10 * time.Second
+
 type MailDialer struct {
-	dialer       *gomail.Dialer
+	host         string
+	port         int
+	username     string
+	password     string
 	from         string
 	baseFilePath string
 }
@@ -41,24 +52,25 @@ func NewMailDialer() (*MailDialer, error) {
 		return nil, fmt.Errorf("env vars are empty")
 	}
 
-	d := gomail.NewDialer(host, port, username, password)
-
 	return &MailDialer{
-		dialer:       d,
+		host:         host,
+		port:         port,
+		username:     username,
+		password:     password,
 		from:         from,
 		baseFilePath: baseFilePath,
 	}, nil
 }
 
-func (md *MailDialer) ExecuteTask(rawPayload interface{}) error {
+func (md *MailDialer) ExecuteTask(ctx context.Context, rawPayload interface{}) (map[string]interface{}, error) {
 	data, err := json.Marshal(rawPayload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal rawPayload: %v", err)
+		return nil, fmt.Errorf("failed to marshal rawPayload: %v", err)
 	}
 
 	var payload task.SendEmailPayload
 	if err := json.Unmarshal(data, &payload); err != nil {
-		return fmt.Errorf("failed to unmarshal payload to SendEmailPayload: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal payload to SendEmailPayload: %v", err)
 	}
 
 	m := gomail.NewMessage()
@@ -80,9 +92,87 @@ func (md *MailDialer) ExecuteTask(rawPayload interface{}) error {
 		}
 	}
 
-	if err := md.dialer.DialAndSend(m); err != nil {
-		return fmt.Errorf("failed to send mail: %v", err)
+	if err := md.send(ctx, payload.To, m); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("sending mail was aborted: %v", ctx.Err())
+		}
+		return nil, fmt.Errorf("failed to send mail: %v", err)
+	}
+
+	return nil, nil
+}
+
+func (md *MailDialer) send(ctx context.Context, to string, m *gomail.Message) error {
+	addr := net.JoinHostPort(md.host, strconv.Itoa(md.port))
+	tlsConfig := &tls.Config{ServerName: md.host}
+	dialer := &net.Dialer{Timeout: dialTimeout}
+
+	var conn net.Conn
+	var err error
+	if md.port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to dial mail server: %v", err)
 	}
 
-	return nil
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, md.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to create smtp client: %v", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start tls: %v", err)
+		}
+	}
+
+	if ok, mechanisms := c.Extension("AUTH"); ok {
+		var auth smtp.Auth
+		if containsMechanism(mechanisms, "CRAM-MD5") {
+			auth = smtp.CRAMMD5Auth(md.username, md.password)
+		} else {
+			auth = smtp.PlainAuth("", md.username, md.password, md.host)
+		}
+
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %v", err)
+		}
+	}
+
+	if err := c.Mail(md.from); err != nil {
+		return fmt.Errorf("failed to set sender: %v", err)
+	}
+	if err := c.Rcpt(to); err != nil {
+		return fmt.Errorf("failed to set recipient: %v", err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start data: %v", err)
+	}
+	if _, err := m.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write message: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finish data: %v", err)
+	}
+
+	return c.Quit()
+}
+
+func containsMechanism(mechanisms, mechanism string) bool {
+	for _, m := range strings.Fields(mechanisms) {
+		if m == mechanism {
+			return true
+		}
+	}
+	return false
 }
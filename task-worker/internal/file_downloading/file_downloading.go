@@ -1,6 +1,7 @@
 package file_downloading
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -27,36 +28,52 @@ func NewFileDownloader() *FileDownloader {
 	}
 }
 
-func (fd *FileDownloader) ExecuteTask(rawPayload interface{}) error {
+func (fd *FileDownloader) ExecuteTask(ctx context.Context, rawPayload interface{}) (map[string]interface{}, error) {
 	data, err := json.Marshal(rawPayload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal rawPayload: %v", err)
+		return nil, fmt.Errorf("failed to marshal rawPayload: %v", err)
 	}
 
 	var payload task.FileDownloadingPayload
 	if err := json.Unmarshal(data, &payload); err != nil {
-		return fmt.Errorf("failed to unmarshal payload to FileDownloadingPayload: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal payload to FileDownloadingPayload: %v", err)
 	}
 
 	client := http.Client{
 		Timeout: 15 * time.Second,
 	}
 
+	paths := make([]interface{}, len(payload.URLs))
 	errs := []error{}
 	mu := sync.Mutex{}
 	sem := make(chan struct{}, 5)
 	wg := sync.WaitGroup{}
 
-	for _, url := range payload.URLs {
+	for i, url := range payload.URLs {
 		wg.Add(1)
 
-		go func(url string) {
+		go func(i int, url string) {
 			defer wg.Done()
 
-			sem <- struct{}{}
-			defer func() { <-sem }()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				mu.Lock()
+				errs = append(errs, ctx.Err())
+				mu.Unlock()
+				return
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				return
+			}
 
-			resp, err := client.Get(url)
+			resp, err := client.Do(req)
 			if err != nil {
 				mu.Lock()
 				errs = append(errs, err)
@@ -89,7 +106,8 @@ func (fd *FileDownloader) ExecuteTask(rawPayload interface{}) error {
 				ext = exts[0]
 			}
 
-			srcPath := filepath.Join(fd.baseFilePath, uuid.New().String()+ext)
+			fileName := uuid.New().String() + ext
+			srcPath := filepath.Join(fd.baseFilePath, fileName)
 			out, err := os.Create(srcPath)
 			if err != nil {
 				mu.Lock()
@@ -101,24 +119,31 @@ func (fd *FileDownloader) ExecuteTask(rawPayload interface{}) error {
 
 			_, err = io.Copy(out, resp.Body)
 			if err != nil {
+				os.Remove(srcPath)
 				mu.Lock()
 				errs = append(errs, err)
 				mu.Unlock()
 				return
 			}
-		}(url)
+
+			paths[i] = fileName
+		}(i, url)
 	}
 
 	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("downloading was aborted: %v", err)
+	}
+
 	if len(errs) > 0 {
 		var sb strings.Builder
 		sb.WriteString("some files failed to download:")
 		for _, err := range errs {
 			sb.WriteString(" - " + err.Error() + " - ")
 		}
-		return errors.New(sb.String())
+		return nil, errors.New(sb.String())
 	}
 
-	return nil
+	return map[string]interface{}{"paths": paths}, nil
 }
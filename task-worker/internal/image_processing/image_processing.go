@@ -1,8 +1,10 @@
 package image_processing
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"image"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,43 +24,54 @@ func NewImageProcessor() *ImageProcessor {
 	}
 }
 
-func (ip *ImageProcessor) ExecuteTask(rawPayload interface{}) error {
+func (ip *ImageProcessor) ExecuteTask(ctx context.Context, rawPayload interface{}) (map[string]interface{}, error) {
 	data, err := json.Marshal(rawPayload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal rawPayload: %v", err)
+		return nil, fmt.Errorf("failed to marshal rawPayload: %v", err)
 	}
 
 	var payload task.ImageProcessingPayload
 	if err := json.Unmarshal(data, &payload); err != nil {
-		return fmt.Errorf("failed to unmarshal payload to ImageProcessingPayload: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal payload to ImageProcessingPayload: %v", err)
 	}
 
 	srcPath := filepath.Join(ip.baseFilePath, payload.Path)
 	src, err := imaging.Open(srcPath)
 	if err != nil {
-		return fmt.Errorf("failed to open source image: %v", err)
+		return nil, fmt.Errorf("failed to open source image: %v", err)
 	}
 
+	steps := []func(img image.Image) image.Image{}
 	if payload.Grayscale {
-		src = imaging.Grayscale(src)
+		steps = append(steps, func(img image.Image) image.Image { return imaging.Grayscale(img) })
 	}
 	if payload.Invert {
-		src = imaging.Invert(src)
+		steps = append(steps, func(img image.Image) image.Image { return imaging.Invert(img) })
 	}
-	src = imaging.Blur(src, payload.Blur)
-	src = imaging.Sharpen(src, payload.Sharpen)
-	src = imaging.AdjustGamma(src, payload.Gamma)
-	src = imaging.AdjustContrast(src, payload.Contrast)
-	src = imaging.AdjustBrightness(src, payload.Brightness)
-	src = imaging.AdjustSaturation(src, payload.Saturation)
+	steps = append(steps,
+		func(img image.Image) image.Image { return imaging.Blur(img, payload.Blur) },
+		func(img image.Image) image.Image { return imaging.Sharpen(img, payload.Sharpen) },
+		func(img image.Image) image.Image { return imaging.AdjustGamma(img, payload.Gamma) },
+		func(img image.Image) image.Image { return imaging.AdjustContrast(img, payload.Contrast) },
+		func(img image.Image) image.Image { return imaging.AdjustBrightness(img, payload.Brightness) },
+		func(img image.Image) image.Image { return imaging.AdjustSaturation(img, payload.Saturation) },
+	)
 
-	lastPointIndex := strings.LastIndex(srcPath, ".")
+	var img image.Image = src
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("image processing was aborted: %v", err)
+		}
+		img = step(img)
+	}
+
+	lastPointIndex := strings.LastIndex(payload.Path, ".")
 
-	dstPath := srcPath[:lastPointIndex] + "_" + uuid.New().String() + srcPath[lastPointIndex:]
-	err = imaging.Save(src, dstPath)
+	dstName := payload.Path[:lastPointIndex] + "_" + uuid.New().String() + payload.Path[lastPointIndex:]
+	err = imaging.Save(img, filepath.Join(ip.baseFilePath, dstName))
 	if err != nil {
-		return fmt.Errorf("failed to save image: %v", err)
+		return nil, fmt.Errorf("failed to save image: %v", err)
 	}
 
-	return nil
+	return map[string]interface{}{"path": dstName}, nil
 }
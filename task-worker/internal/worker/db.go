@@ -1,9 +1,19 @@
 package worker
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type DB interface {
-	UpdateStatusOfTask(taskID uuid.UUID, status string) error
+	StartTask(taskID uuid.UUID, redelivered bool) (uint8, error)
+	CompleteTask(taskID uuid.UUID, result map[string]interface{}) error
+	FailTask(taskID uuid.UUID) error
+	ReleaseTask(taskID uuid.UUID) error
+	PostponeTask(taskID uuid.UUID, delay time.Duration) error
+	GetStatusOfTask(taskID uuid.UUID) (string, error)
+	GetResultsOfTasks(taskIDs []uuid.UUID) (map[uuid.UUID]map[string]interface{}, error)
+	StartAttempt(taskID uuid.UUID, workerID string) (uint64, error)
+	FinishAttempt(attemptID uint64, errorMessage *string) error
 }
@@ -1,5 +1,7 @@
 package worker
 
+import "context"
+
 type Executer interface {
-	ExecuteTask(rawPayload interface{}) error
+	ExecuteTask(ctx context.Context, rawPayload interface{}) (map[string]interface{}, error)
 }
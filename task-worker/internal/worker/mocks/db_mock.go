@@ -0,0 +1,172 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/worker/db.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/worker/db.go -destination=internal/worker/mocks/db_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDB is a mock of DB interface.
+type MockDB struct {
+	ctrl     *gomock.Controller
+	recorder *MockDBMockRecorder
+	isgomock struct{}
+}
+
+// MockDBMockRecorder is the mock recorder for MockDB.
+type MockDBMockRecorder struct {
+	mock *MockDB
+}
+
+// NewMockDB creates a new mock instance.
+func NewMockDB(ctrl *gomock.Controller) *MockDB {
+	mock := &MockDB{ctrl: ctrl}
+	mock.recorder = &MockDBMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDB) EXPECT() *MockDBMockRecorder {
+	return m.recorder
+}
+
+// CompleteTask mocks base method.
+func (m *MockDB) CompleteTask(taskID uuid.UUID, result map[string]any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTask", taskID, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteTask indicates an expected call of CompleteTask.
+func (mr *MockDBMockRecorder) CompleteTask(taskID, result any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTask", reflect.TypeOf((*MockDB)(nil).CompleteTask), taskID, result)
+}
+
+// FailTask mocks base method.
+func (m *MockDB) FailTask(taskID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailTask", taskID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailTask indicates an expected call of FailTask.
+func (mr *MockDBMockRecorder) FailTask(taskID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailTask", reflect.TypeOf((*MockDB)(nil).FailTask), taskID)
+}
+
+// FinishAttempt mocks base method.
+func (m *MockDB) FinishAttempt(attemptID uint64, errorMessage *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishAttempt", attemptID, errorMessage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishAttempt indicates an expected call of FinishAttempt.
+func (mr *MockDBMockRecorder) FinishAttempt(attemptID, errorMessage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishAttempt", reflect.TypeOf((*MockDB)(nil).FinishAttempt), attemptID, errorMessage)
+}
+
+// GetResultsOfTasks mocks base method.
+func (m *MockDB) GetResultsOfTasks(taskIDs []uuid.UUID) (map[uuid.UUID]map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResultsOfTasks", taskIDs)
+	ret0, _ := ret[0].(map[uuid.UUID]map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResultsOfTasks indicates an expected call of GetResultsOfTasks.
+func (mr *MockDBMockRecorder) GetResultsOfTasks(taskIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResultsOfTasks", reflect.TypeOf((*MockDB)(nil).GetResultsOfTasks), taskIDs)
+}
+
+// GetStatusOfTask mocks base method.
+func (m *MockDB) GetStatusOfTask(taskID uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusOfTask", taskID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusOfTask indicates an expected call of GetStatusOfTask.
+func (mr *MockDBMockRecorder) GetStatusOfTask(taskID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusOfTask", reflect.TypeOf((*MockDB)(nil).GetStatusOfTask), taskID)
+}
+
+// PostponeTask mocks base method.
+func (m *MockDB) PostponeTask(taskID uuid.UUID, delay time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostponeTask", taskID, delay)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostponeTask indicates an expected call of PostponeTask.
+func (mr *MockDBMockRecorder) PostponeTask(taskID, delay any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostponeTask", reflect.TypeOf((*MockDB)(nil).PostponeTask), taskID, delay)
+}
+
+// ReleaseTask mocks base method.
+func (m *MockDB) ReleaseTask(taskID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseTask", taskID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseTask indicates an expected call of ReleaseTask.
+func (mr *MockDBMockRecorder) ReleaseTask(taskID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseTask", reflect.TypeOf((*MockDB)(nil).ReleaseTask), taskID)
+}
+
+// StartAttempt mocks base method.
+func (m *MockDB) StartAttempt(taskID uuid.UUID, workerID string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartAttempt", taskID, workerID)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartAttempt indicates an expected call of StartAttempt.
+func (mr *MockDBMockRecorder) StartAttempt(taskID, workerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartAttempt", reflect.TypeOf((*MockDB)(nil).StartAttempt), taskID, workerID)
+}
+
+// StartTask mocks base method.
+func (m *MockDB) StartTask(taskID uuid.UUID, redelivered bool) (uint8, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartTask", taskID, redelivered)
+	ret0, _ := ret[0].(uint8)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartTask indicates an expected call of StartTask.
+func (mr *MockDBMockRecorder) StartTask(taskID, redelivered any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTask", reflect.TypeOf((*MockDB)(nil).StartTask), taskID, redelivered)
+}
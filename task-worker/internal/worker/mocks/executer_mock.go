@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/worker/executer.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/worker/executer.go -destination=internal/worker/mocks/executer_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockExecuter is a mock of Executer interface.
+type MockExecuter struct {
+	ctrl     *gomock.Controller
+	recorder *MockExecuterMockRecorder
+	isgomock struct{}
+}
+
+// MockExecuterMockRecorder is the mock recorder for MockExecuter.
+type MockExecuterMockRecorder struct {
+	mock *MockExecuter
+}
+
+// NewMockExecuter creates a new mock instance.
+func NewMockExecuter(ctrl *gomock.Controller) *MockExecuter {
+	mock := &MockExecuter{ctrl: ctrl}
+	mock.recorder = &MockExecuterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExecuter) EXPECT() *MockExecuterMockRecorder {
+	return m.recorder
+}
+
+// ExecuteTask mocks base method.
+func (m *MockExecuter) ExecuteTask(ctx context.Context, rawPayload any) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteTask", ctx, rawPayload)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteTask indicates an expected call of ExecuteTask.
+func (mr *MockExecuterMockRecorder) ExecuteTask(ctx, rawPayload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteTask", reflect.TypeOf((*MockExecuter)(nil).ExecuteTask), ctx, rawPayload)
+}
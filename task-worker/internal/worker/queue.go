@@ -1,9 +1,9 @@
 package worker
 
 import (
-	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/imightbuyaboat/TaskFlow/pkg/queue"
 )
 
 type Queue interface {
-	NewConsumerChannel() (*amqp.Channel, <-chan amqp.Delivery, error)
+	NewConsumer(taskType string) (queue.Consumer, error)
 }
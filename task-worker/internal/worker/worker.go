@@ -1,84 +1,298 @@
 package worker
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/metrics"
+	"github.com/imightbuyaboat/TaskFlow/pkg/queue"
 	"github.com/imightbuyaboat/TaskFlow/pkg/task"
 	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/db"
-	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
+const cancellationCheckInterval = 2 * time.Second
+
 type Worker struct {
-	id        int
-	ch        *amqp.Channel
-	msgs      <-chan amqp.Delivery
-	executers map[string]Executer
-	db        DB
-	logger    *zap.Logger
+	id       int
+	running  atomic.Bool
+	workerID string
+	consumer queue.Consumer
+	msgs     <-chan queue.Delivery
+	taskType string
+	executer Executer
+	db       DB
+	logger   *zap.Logger
 }
 
-func NewWorker(id int, q Queue, executers map[string]Executer, db DB, logger *zap.Logger) (*Worker, error) {
-	ch, msgs, err := q.NewConsumerChannel()
+func NewWorker(id int, taskType string, q Queue, executer Executer, db DB, logger *zap.Logger) (*Worker, error) {
+	consumer, err := q.NewConsumer(taskType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create channel: %v", err)
+		return nil, fmt.Errorf("failed to create consumer: %v", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
 	}
 
 	return &Worker{
-		id:        id,
-		ch:        ch,
-		msgs:      msgs,
-		executers: executers,
-		db:        db,
-		logger:    logger,
+		id:       id,
+		workerID: fmt.Sprintf("%s-%d", hostname, id),
+		consumer: consumer,
+		msgs:     consumer.Deliveries(),
+		taskType: taskType,
+		executer: executer,
+		db:       db,
+		logger:   logger,
 	}, nil
 }
 
-func (w *Worker) Work() {
-	defer w.ch.Close()
+func (w *Worker) Work(ctx, execCtx context.Context) {
+	w.running.Store(true)
+	defer w.running.Store(false)
+	defer w.consumer.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("worker stopped", zap.Int("worker", w.id))
+			return
+		case d, ok := <-w.msgs:
+			if !ok {
+				w.logger.Info("delivery channel closed", zap.Int("worker", w.id))
+				return
+			}
+			if ctx.Err() != nil {
+				d.Nack(true)
+				w.logger.Info("worker stopped", zap.Int("worker", w.id))
+				return
+			}
+			w.processMsg(execCtx, &d)
+		}
+	}
+}
 
-	for d := range w.msgs {
-		w.processMsg(&d)
+func (w *Worker) Check(ctx context.Context) error {
+	if !w.running.Load() {
+		return fmt.Errorf("worker %d is not running", w.id)
 	}
+
+	return nil
 }
 
-func (w *Worker) processMsg(d *amqp.Delivery) {
+func (w *Worker) processMsg(execCtx context.Context, d *queue.Delivery) {
 	w.logger.Info("successfully delivered message", zap.Int("worker", w.id))
 
 	var t task.Task
 	if err := json.Unmarshal(d.Body, &t); err != nil {
 		w.logger.Error("failed to unmarshal body of message", zap.Error(err), zap.Int("worker", w.id))
-		d.Nack(false, false)
+		d.Nack(false)
 		return
 	}
 
-	if err := w.db.UpdateStatusOfTask(t.ID, "processing"); err != nil {
+	if t.Type != w.taskType {
+		w.logger.Error("unexpected type of task", zap.String("type", t.Type), zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+		d.Nack(false)
+		return
+	}
+
+	attempt, err := w.db.StartTask(t.ID, d.Redelivered)
+	if err != nil {
 		if err == db.ErrMaxRetriesReached {
 			w.logger.Info("reached max retries", zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
-			d.Nack(false, false)
+
+			if err := w.db.FailTask(t.ID); err != nil {
+				w.logger.Error("failed to update status of task", zap.Error(err), zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+			} else {
+				metrics.TasksFailed.WithLabelValues(t.Type).Inc()
+			}
+			d.Nack(false)
+			return
+		}
+
+		if err == db.ErrTaskCancelled {
+			w.logger.Info("skip cancelled task", zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+			d.Ack()
+			return
+		}
+
+		if err == db.ErrTaskFinished {
+			w.logger.Info("skip finished task", zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+			d.Ack()
+			return
+		}
+
+		if err == db.ErrTaskNotQueued {
+			w.logger.Info("skip task that is not queued", zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+			d.Ack()
 			return
 		}
 
 		w.logger.Error("failed to update status of task", zap.Error(err), zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
-		d.Nack(false, false)
+		d.Nack(false)
 		return
 	}
 
-	if err := w.executers[t.Type].ExecuteTask(t.Payload); err != nil {
+	attemptID, err := w.db.StartAttempt(t.ID, w.workerID)
+	if err != nil {
+		w.logger.Error("failed to start attempt", zap.Error(err), zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+	}
+
+	ctx, cancel := w.watchCancellation(execCtx, t.ID)
+	defer cancel()
+
+	start := time.Now()
+	result, err := w.executeTask(ctx, &t)
+	w.observeExecution(t.Type, start, err, ctx.Err() != nil)
+	if attemptID != 0 {
+		w.finishAttempt(attemptID, t.ID, err)
+	}
+
+	if err != nil {
+		if execCtx.Err() != nil {
+			w.logger.Info("task was interrupted by shutdown", zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+
+			if err := w.db.ReleaseTask(t.ID); err != nil {
+				w.logger.Error("failed to release task", zap.Error(err), zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+			}
+			d.Nack(true)
+			return
+		}
+
+		if ctx.Err() != nil {
+			w.logger.Info("task was cancelled during execution", zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+			d.Ack()
+			return
+		}
+
 		w.logger.Error("failed to execute task", zap.Error(err), zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
 
-		if err := w.db.UpdateStatusOfTask(t.ID, "error"); err != nil {
-			w.logger.Error("failed to update status of task", zap.Error(err), zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+		if attempt >= t.MaxRetries {
+			w.logger.Info("reached max retries", zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+
+			if err := w.db.FailTask(t.ID); err != nil {
+				w.logger.Error("failed to update status of task", zap.Error(err), zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+			} else {
+				metrics.TasksFailed.WithLabelValues(t.Type).Inc()
+			}
+			d.Nack(false)
+			return
+		}
+
+		retryPolicy := task.DefaultRetryPolicy
+		if t.RetryPolicy != nil {
+			retryPolicy = *t.RetryPolicy
+		}
+		delay := retryPolicy.NextDelay(attempt)
+
+		if err := w.db.PostponeTask(t.ID, delay); err != nil {
+			w.logger.Error("failed to postpone task", zap.Error(err), zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+			d.Nack(true)
+			return
 		}
-		d.Nack(false, true)
+
+		w.logger.Info("postponed task for retry", zap.Int("worker", w.id), zap.String("task_id", t.ID.String()), zap.Duration("delay", delay))
+		d.Ack()
 		return
 	}
 
 	w.logger.Info("succesfully complete task", zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
 
-	if err := w.db.UpdateStatusOfTask(t.ID, "done"); err != nil {
+	if err := w.db.CompleteTask(t.ID, result); err != nil {
 		w.logger.Error("failed to update status of task", zap.Error(err), zap.Int("worker", w.id), zap.String("task_id", t.ID.String()))
+	} else {
+		metrics.TasksCompleted.WithLabelValues(t.Type).Inc()
+	}
+	d.Ack()
+}
+
+func (w *Worker) executeTask(ctx context.Context, t *task.Task) (map[string]interface{}, error) {
+	payload, err := w.resolvePayload(t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve payload: %v", err)
+	}
+
+	return w.executer.ExecuteTask(ctx, payload)
+}
+
+func (w *Worker) resolvePayload(t *task.Task) (map[string]interface{}, error) {
+	refs := task.References(t.Payload)
+	if len(refs) == 0 {
+		return t.Payload, nil
 	}
-	d.Ack(false)
+
+	taskIDs := make([]uuid.UUID, 0, len(refs))
+	for _, ref := range refs {
+		taskID, err := uuid.Parse(ref.Task)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task in reference %s", ref)
+		}
+		taskIDs = append(taskIDs, taskID)
+	}
+
+	results, err := w.db.GetResultsOfTasks(taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return task.ResolveReferences(t.Payload, results)
+}
+
+func (w *Worker) observeExecution(taskType string, start time.Time, execErr error, cancelled bool) {
+	result := "success"
+	if cancelled {
+		result = "cancelled"
+	} else if execErr != nil {
+		result = "failure"
+	}
+
+	metrics.TaskExecutionDuration.WithLabelValues(taskType, result).Observe(time.Since(start).Seconds())
+}
+
+func (w *Worker) finishAttempt(attemptID uint64, taskID uuid.UUID, execErr error) {
+	var errorMessage *string
+	if execErr != nil {
+		msg := execErr.Error()
+		errorMessage = &msg
+	}
+
+	if err := w.db.FinishAttempt(attemptID, errorMessage); err != nil {
+		w.logger.Error("failed to finish attempt", zap.Error(err), zap.Int("worker", w.id), zap.String("task_id", taskID.String()))
+	}
+}
+
+func (w *Worker) watchCancellation(parent context.Context, taskID uuid.UUID) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	go func() {
+		ticker := time.NewTicker(cancellationCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				status, err := w.db.GetStatusOfTask(taskID)
+				if err != nil {
+					w.logger.Error("failed to get status of task", zap.Error(err), zap.Int("worker", w.id), zap.String("task_id", taskID.String()))
+					continue
+				}
+
+				if status == "cancelled" {
+					w.logger.Info("received cancellation of task", zap.Int("worker", w.id), zap.String("task_id", taskID.String()))
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, cancel
 }
@@ -0,0 +1,211 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/imightbuyaboat/TaskFlow/pkg/queue"
+	"github.com/imightbuyaboat/TaskFlow/pkg/task"
+	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/db"
+	"github.com/imightbuyaboat/TaskFlow/task-worker/internal/worker/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestProcessMsg(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockDB(ctrl)
+	mockExecuter := mocks.NewMockExecuter(ctrl)
+	logger := zaptest.NewLogger(t)
+
+	q := queue.NewMemoryQueue()
+	defer q.Close()
+
+	w, err := NewWorker(1, "send_email", q, mockExecuter, mockDB, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.consumer.Close()
+
+	payload := map[string]interface{}{"to": "test@test.com", "subject": "hello"}
+	retryPolicy := task.RetryPolicy{InitialDelayMs: 500, Multiplier: 2, MaxDelayMs: 10000}
+	emailTask := task.Task{
+		ID:          uuid.New(),
+		UserID:      1,
+		Type:        "send_email",
+		Payload:     payload,
+		Status:      "queued",
+		MaxRetries:  3,
+		RetryPolicy: &retryPolicy,
+	}
+	imageTask := emailTask
+	imageTask.Type = "process_image"
+
+	parentID := uuid.New()
+	dependentTask := emailTask
+	dependentTask.Payload = map[string]interface{}{
+		"to":             "test@test.com",
+		"attached_files": []interface{}{"{{ " + parentID.String() + ".path }}"},
+	}
+	resolvedPayload := map[string]interface{}{
+		"to":             "test@test.com",
+		"attached_files": []interface{}{"image_processed.png"},
+	}
+	parentResults := map[uuid.UUID]map[string]interface{}{
+		parentID: {"path": "image_processed.png"},
+	}
+
+	body := func(t task.Task) []byte {
+		b, _ := json.Marshal(t)
+		return b
+	}
+	attemptID := uint64(10)
+	execErr := errors.New("smtp error")
+
+	tests := []struct {
+		name              string
+		body              []byte
+		mockSetup         func(db *mocks.MockDB, e *mocks.MockExecuter)
+		expectedDeadCount int
+	}{
+		{
+			name: "Successfully complete task",
+			body: body(emailTask),
+			mockSetup: func(mdb *mocks.MockDB, e *mocks.MockExecuter) {
+				mdb.EXPECT().StartTask(emailTask.ID, false).Return(uint8(1), nil)
+				mdb.EXPECT().StartAttempt(emailTask.ID, w.workerID).Return(attemptID, nil)
+				e.EXPECT().ExecuteTask(gomock.Any(), payload).Return(nil, nil)
+				mdb.EXPECT().FinishAttempt(attemptID, nil).Return(nil)
+				mdb.EXPECT().CompleteTask(emailTask.ID, nil).Return(nil)
+			},
+			expectedDeadCount: 0,
+		},
+		{
+			name: "Resolve reference to output of parent task",
+			body: body(dependentTask),
+			mockSetup: func(mdb *mocks.MockDB, e *mocks.MockExecuter) {
+				mdb.EXPECT().StartTask(dependentTask.ID, false).Return(uint8(1), nil)
+				mdb.EXPECT().StartAttempt(dependentTask.ID, w.workerID).Return(attemptID, nil)
+				mdb.EXPECT().GetResultsOfTasks([]uuid.UUID{parentID}).Return(parentResults, nil)
+				e.EXPECT().ExecuteTask(gomock.Any(), resolvedPayload).Return(map[string]interface{}{"sent": true}, nil)
+				mdb.EXPECT().FinishAttempt(attemptID, nil).Return(nil)
+				mdb.EXPECT().CompleteTask(dependentTask.ID, map[string]interface{}{"sent": true}).Return(nil)
+			},
+			expectedDeadCount: 0,
+		},
+		{
+			name: "Missing output of parent task postpones task",
+			body: body(dependentTask),
+			mockSetup: func(mdb *mocks.MockDB, e *mocks.MockExecuter) {
+				mdb.EXPECT().StartTask(dependentTask.ID, false).Return(uint8(1), nil)
+				mdb.EXPECT().StartAttempt(dependentTask.ID, w.workerID).Return(attemptID, nil)
+				mdb.EXPECT().GetResultsOfTasks([]uuid.UUID{parentID}).Return(map[uuid.UUID]map[string]interface{}{}, nil)
+				mdb.EXPECT().FinishAttempt(attemptID, gomock.Any()).Return(nil)
+				mdb.EXPECT().PostponeTask(dependentTask.ID, retryPolicy.NextDelay(1)).Return(nil)
+			},
+			expectedDeadCount: 0,
+		},
+		{
+			name: "Executer failure postpones task",
+			body: body(emailTask),
+			mockSetup: func(mdb *mocks.MockDB, e *mocks.MockExecuter) {
+				mdb.EXPECT().StartTask(emailTask.ID, false).Return(uint8(1), nil)
+				mdb.EXPECT().StartAttempt(emailTask.ID, w.workerID).Return(attemptID, nil)
+				e.EXPECT().ExecuteTask(gomock.Any(), payload).Return(nil, execErr)
+				mdb.EXPECT().FinishAttempt(attemptID, gomock.Any()).DoAndReturn(func(id uint64, errorMessage *string) error {
+					assert.Equal(t, execErr.Error(), *errorMessage)
+					return nil
+				})
+				mdb.EXPECT().PostponeTask(emailTask.ID, retryPolicy.NextDelay(1)).Return(nil)
+			},
+			expectedDeadCount: 0,
+		},
+		{
+			name: "Executer failure on last attempt fails task",
+			body: body(emailTask),
+			mockSetup: func(mdb *mocks.MockDB, e *mocks.MockExecuter) {
+				mdb.EXPECT().StartTask(emailTask.ID, false).Return(uint8(3), nil)
+				mdb.EXPECT().StartAttempt(emailTask.ID, w.workerID).Return(attemptID, nil)
+				e.EXPECT().ExecuteTask(gomock.Any(), payload).Return(nil, execErr)
+				mdb.EXPECT().FinishAttempt(attemptID, gomock.Any()).Return(nil)
+				mdb.EXPECT().FailTask(emailTask.ID).Return(nil)
+			},
+			expectedDeadCount: 1,
+		},
+		{
+			name: "Max retries reached before execution",
+			body: body(emailTask),
+			mockSetup: func(mdb *mocks.MockDB, e *mocks.MockExecuter) {
+				mdb.EXPECT().StartTask(emailTask.ID, false).Return(uint8(0), db.ErrMaxRetriesReached)
+				mdb.EXPECT().FailTask(emailTask.ID).Return(nil)
+			},
+			expectedDeadCount: 1,
+		},
+		{
+			name: "Skip cancelled task",
+			body: body(emailTask),
+			mockSetup: func(mdb *mocks.MockDB, e *mocks.MockExecuter) {
+				mdb.EXPECT().StartTask(emailTask.ID, false).Return(uint8(0), db.ErrTaskCancelled)
+			},
+			expectedDeadCount: 0,
+		},
+		{
+			name: "Skip finished task",
+			body: body(emailTask),
+			mockSetup: func(mdb *mocks.MockDB, e *mocks.MockExecuter) {
+				mdb.EXPECT().StartTask(emailTask.ID, false).Return(uint8(0), db.ErrTaskFinished)
+			},
+			expectedDeadCount: 0,
+		},
+		{
+			name: "Skip task that is not queued",
+			body: body(emailTask),
+			mockSetup: func(mdb *mocks.MockDB, e *mocks.MockExecuter) {
+				mdb.EXPECT().StartTask(emailTask.ID, false).Return(uint8(0), db.ErrTaskNotQueued)
+			},
+			expectedDeadCount: 0,
+		},
+		{
+			name:              "Malformed message",
+			body:              []byte("not a task"),
+			mockSetup:         func(mdb *mocks.MockDB, e *mocks.MockExecuter) {},
+			expectedDeadCount: 1,
+		},
+		{
+			name:              "Unexpected type of task",
+			body:              body(imageTask),
+			mockSetup:         func(mdb *mocks.MockDB, e *mocks.MockExecuter) {},
+			expectedDeadCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup(mockDB, mockExecuter)
+
+			deadBefore := len(q.DeadLetters())
+			if err := q.PublishRaw("send_email", tt.body); err != nil {
+				t.Fatal(err)
+			}
+
+			var d queue.Delivery
+			select {
+			case d = <-w.msgs:
+			case <-time.After(time.Second):
+				t.Fatal("message was not delivered")
+			}
+
+			w.processMsg(context.Background(), &d)
+
+			assert.Equal(t, tt.expectedDeadCount, len(q.DeadLetters())-deadBefore)
+			assert.Equal(t, 0, q.Len())
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/imightbuyaboat/TaskFlow/pkg/config"
+	"github.com/imightbuyaboat/TaskFlow/pkg/health"
+	"github.com/imightbuyaboat/TaskFlow/pkg/logger"
+	"github.com/imightbuyaboat/TaskFlow/pkg/queue"
+	apiapp "github.com/imightbuyaboat/TaskFlow/task-api/app"
+	schedulerapp "github.com/imightbuyaboat/TaskFlow/task-scheduler/app"
+	workerapp "github.com/imightbuyaboat/TaskFlow/task-worker/app"
+	"go.uber.org/zap"
+)
+
+func main() {
+	logger.InitLogger()
+	log := logger.GetLogger()
+
+	cfg := config.Default()
+	cfg.Database.URL = "sqlite://taskflow.db"
+	cfg.API.MigrateOnStart = true
+	cfg.Scheduler.Interval = time.Second
+	cfg.Scheduler.OutboxInterval = 100 * time.Millisecond
+
+	args, err := cfg.Load(os.Args[0], os.Args[1:])
+	if err != nil {
+		log.Fatal("failed to load config", zap.Error(err))
+	}
+
+	if err := logger.SetLevel(cfg.Log.Level); err != nil {
+		log.Fatal("failed to set log level", zap.Error(err))
+	}
+
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := apiapp.Migrate(context.Background(), cfg.DatabaseURL(), args[1:], os.Stdout); err != nil {
+			log.Fatal("failed to migrate db", zap.Error(err))
+		}
+		return
+	}
+
+	log.Info("loaded config", zap.Any("config", cfg.Redacted()))
+
+	q := queue.NewMemoryQueue()
+
+	checker := health.NewChecker()
+	checker.AddReadiness("queue", q.Check)
+
+	api, err := apiapp.New(apiapp.Config{
+		Addr:         cfg.API.Addr,
+		DatabaseURL:  cfg.DatabaseURL(),
+		Migrate:      cfg.API.MigrateOnStart,
+		HealthPrefix: "api_",
+	}, checker, log)
+	if err != nil {
+		log.Fatal("failed to create api", zap.Error(err))
+	}
+
+	scheduler, err := schedulerapp.New(schedulerapp.Config{
+		DatabaseURL:     cfg.DatabaseURL(),
+		Interval:        cfg.Scheduler.Interval,
+		OutboxInterval:  cfg.Scheduler.OutboxInterval,
+		OutboxBatchSize: cfg.Scheduler.OutboxBatchSize,
+		HealthPrefix:    "scheduler_",
+	}, q, checker, log)
+	if err != nil {
+		log.Fatal("failed to create scheduler", zap.Error(err))
+	}
+
+	pools, err := cfg.Worker.Pools()
+	if err != nil {
+		log.Fatal("invalid worker types", zap.Error(err))
+	}
+
+	workers, err := workerapp.New(workerapp.Config{
+		DatabaseURL:  cfg.DatabaseURL(),
+		Pools:        pools,
+		OptionalMail: true,
+		HealthPrefix: "worker_",
+	}, q, checker, log)
+	if err != nil {
+		log.Fatal("failed to create workers", zap.Error(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		if err := api.Run(ctx); err != nil {
+			log.Error("failed to run api", zap.Error(err))
+			stop()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		scheduler.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		workers.Run(ctx)
+	}()
+
+	log.Info("taskflow started", zap.String("addr", cfg.API.Addr), zap.Any("workers", pools))
+
+	wg.Wait()
+	q.Close()
+
+	log.Info("taskflow gracefully stopped")
+}